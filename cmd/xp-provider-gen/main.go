@@ -37,7 +37,14 @@ func main() {
 		cli.WithDefaultProjectVersion(cfgv3.Version),
 		cli.WithPlugins(&crossplanev2.Plugin{}),
 		cli.WithDefaultPlugins(cfgv3.Version, &crossplanev2.Plugin{}),
-		cli.WithExtraCommands(crossplanev2.NewUpdateCommand(), crossplanev2.NewCreateTestCommand()),
+		cli.WithExtraCommands(
+			crossplanev2.NewUpdateCommand(),
+			crossplanev2.NewCreateTestCommand(),
+			crossplanev2.NewDeleteCommand(),
+			crossplanev2.NewCreateVersionCommand(),
+			crossplanev2.NewDoctorCommand(),
+			crossplanev2.NewAddCommand(),
+		),
 		cli.WithCompletion(),
 	)
 	if err != nil {
@@ -0,0 +1,410 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kubebuilder/v4/pkg/config"
+	"sigs.k8s.io/kubebuilder/v4/pkg/model/resource"
+
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/automation"
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/core"
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/openapi"
+)
+
+// providerConfigKind is the --kind add field takes for the ProviderConfig,
+// whose spec is in providerConfigTypes.
+const (
+	providerConfigKind  = "ProviderConfig"
+	providerConfigTypes = "apis/v1alpha1/types.go"
+)
+
+// NewAddCommand returns the `add` command, registered on the CLI via
+// cli.WithExtraCommands (kubebuilder's plugin interface has no add hook).
+func NewAddCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Add to the code of an existing kind",
+	}
+	cmd.AddCommand(newAddFieldCommand())
+	return cmd
+}
+
+// addFieldOptions are the flags of `add field`.
+type addFieldOptions struct {
+	group, version, kind        string
+	params, observe, references []string
+}
+
+func newAddFieldCommand() *cobra.Command {
+	var opts addFieldOptions
+	cmd := &cobra.Command{
+		Use:   "field",
+		Short: "Add spec or status fields to a managed resource kind or the ProviderConfig",
+		Long: `Add fields to a managed resource kind, or to the ProviderConfig.
+
+Each --param becomes a field of the kind's Parameters (spec.forProvider) and each
+--observe one of its Observation (status.atProvider), declared as they are on
+'create api'. Each --reference adds the ID field of another kind of the project,
+with the Ref and Selector fields Crossplane resolves it from. --kind ProviderConfig
+adds --param fields to ProviderConfigSpec in apis/v1alpha1/types.go instead.
+
+The types file is yours, so the fields are inserted into it rather than the file
+being re-rendered: everything else in it stays as you left it. The example manifest
+gains a Ref to the example object of each referenced kind; tests are not changed,
+so give the new fields values there yourself.
+
+The working tree must be clean, so the fields land as one reviewable commit.`,
+		Example: `  # Add a required, immutable region to Bucket's spec and its ARN to its status
+  xp-provider-gen add field --kind Bucket --param region:string,required,immutable --observe arn:string
+
+  # Have Subnet reference a Network, in the network group, by its ID
+  xp-provider-gen add field --kind Subnet --reference networkId=Network.network
+
+  # Add an endpoint setting to the ProviderConfig
+  xp-provider-gen add field --kind ProviderConfig --param endpoint:string`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return runAddField(context.Background(), opts, os.Stdout)
+		},
+	}
+	cmd.Flags().StringVar(&opts.kind, "kind", "", "resource kind, or ProviderConfig")
+	cmd.Flags().StringVar(&opts.group, "group", "",
+		"resource group; required only when several groups have the kind")
+	cmd.Flags().StringVar(&opts.version, "version", "",
+		"resource version; required only when the kind is served in several")
+	cmd.Flags().StringArrayVar(&opts.params, "param", nil,
+		"a Parameters field, as name:type[,required][,immutable] (repeatable)")
+	cmd.Flags().StringArrayVar(&opts.observe, "observe", nil, "an Observation field, as name:type (repeatable)")
+	cmd.Flags().StringArrayVar(&opts.references, "reference", nil,
+		"a reference to another kind, as name=Kind[.group]; the group defaults to the kind's own (repeatable)")
+	_ = cmd.MarkFlagRequired("kind")
+	return cmd
+}
+
+func runAddField(ctx context.Context, opts addFieldOptions, out io.Writer) error {
+	if len(opts.params) == 0 && len(opts.observe) == 0 && len(opts.references) == 0 {
+		return errors.New("nothing to add; declare fields with --param, --observe or --reference")
+	}
+	model, err := openapi.Declare(opts.kind, opts.params, opts.observe)
+	if err != nil {
+		return err
+	}
+	if err := requireCleanTree(ctx); err != nil {
+		return err
+	}
+	st, err := loadProjectStore()
+	if err != nil {
+		return err
+	}
+	pluginConfig, err := loadPluginConfig(st.Config())
+	if err != nil {
+		return err
+	}
+	target, err := fieldTarget(st.Config(), opts)
+	if err != nil {
+		return err
+	}
+	refs, err := addReferences(st.Config(), target.res, model, opts.references)
+	if err != nil {
+		return err
+	}
+	fields := map[string][]openapi.Field{}
+	if len(model.Parameters) > 0 {
+		fields[target.spec] = model.Parameters
+	}
+	if len(model.Observation) > 0 {
+		fields[target.status] = model.Observation
+	}
+
+	disk := afero.NewOsFs()
+	if exists, _ := afero.Exists(disk, target.path); !exists {
+		return fmt.Errorf("%s does not exist", target.path)
+	}
+	if err := rewriteFile(disk, target.path, func(b []byte) ([]byte, error) {
+		return addFields(b, fields)
+	}); err != nil {
+		return err
+	}
+	if len(refs) > 0 {
+		example := path.Join("examples", strings.ToLower(target.res.Group), strings.ToLower(target.res.Kind)+".yaml")
+		if err := rewriteFile(disk, example, func(b []byte) ([]byte, error) {
+			return addExampleReferences(b, refs), nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	structs := make([]string, 0, len(fields))
+	var names []string
+	for name, fs := range fields {
+		structs = append(structs, name)
+		for _, f := range fs {
+			names = append(names, f.Name)
+		}
+	}
+	sort.Strings(structs)
+	sort.Strings(names)
+	fmt.Fprintf(out, "Added %s to %s in %s.\n", strings.Join(names, ", "), strings.Join(structs, " and "), target.path)
+
+	pipeline := automation.NewAddFieldPipeline(pluginConfig, strings.Join(structs, " and "), names)
+	fmt.Fprintln(out, "Running post-scaffolding automation...")
+	if err := pipeline.Run(); err != nil {
+		return fmt.Errorf("post-scaffolding automation: %w", err)
+	}
+	return nil
+}
+
+// fieldsTarget is the file add field edits and the structs in it that take
+// Parameters and Observation fields; status is empty when there are none. res
+// is the kind, and nil for the ProviderConfig.
+type fieldsTarget struct {
+	path, spec, status string
+	res                *resource.Resource
+}
+
+// fieldTarget finds the types file of the kind opts names, at its one version
+// or at --version.
+func fieldTarget(cfg config.Config, opts addFieldOptions) (fieldsTarget, error) {
+	if opts.kind == providerConfigKind && opts.group == "" {
+		if len(opts.observe) > 0 {
+			return fieldsTarget{}, errors.New("a ProviderConfig has no observed fields; use --param")
+		}
+		if len(opts.references) > 0 {
+			return fieldsTarget{}, errors.New("a ProviderConfig cannot reference a managed resource")
+		}
+		return fieldsTarget{path: providerConfigTypes, spec: "ProviderConfigSpec"}, nil
+	}
+
+	all, err := cfg.GetResources()
+	if err != nil {
+		return fieldsTarget{}, fmt.Errorf("reading project resources: %w", err)
+	}
+	groups := map[string]bool{}
+	var matches []resource.Resource
+	for _, r := range all {
+		if r.Kind != opts.kind || opts.group != "" && r.Group != opts.group {
+			continue
+		}
+		groups[r.Group] = true
+		if opts.version == "" || r.Version == opts.version {
+			matches = append(matches, r)
+		}
+	}
+	switch {
+	case len(groups) > 1:
+		return fieldsTarget{}, fmt.Errorf("kind %s is in several groups; pick one with --group", opts.kind)
+	case len(matches) == 0 && opts.version != "":
+		return fieldsTarget{}, fmt.Errorf("kind %s has no version %s in this project", opts.kind, opts.version)
+	case len(matches) == 0:
+		return fieldsTarget{}, fmt.Errorf("kind %s is not in this project", opts.kind)
+	case len(matches) > 1:
+		versions := make([]string, 0, len(matches))
+		for _, r := range matches {
+			versions = append(versions, r.Version)
+		}
+		sort.Strings(versions)
+		return fieldsTarget{}, fmt.Errorf("kind %s is served in %s; pick one with --version",
+			opts.kind, strings.Join(versions, ", "))
+	}
+	res := matches[0]
+	return fieldsTarget{
+		path:   apiFile(res, res.Version, "_types.go"),
+		spec:   res.Kind + "Parameters",
+		status: res.Kind + "Observation",
+		res:    &res,
+	}, nil
+}
+
+// addFields inserts fields at the end of the structs they are keyed by, after
+// anything already there, leaving the rest of src as it is. A field whose Go or
+// JSON name a struct already has is refused.
+func addFields(src []byte, fields map[string][]openapi.Field) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	type insertion struct {
+		at   int
+		text string
+	}
+	var inserts []insertion
+	for name, add := range fields {
+		st := findStruct(file, name)
+		if st == nil {
+			return nil, fmt.Errorf("no struct %s", name)
+		}
+		taken := map[string]bool{}
+		for _, f := range st.Fields.List {
+			for _, id := range f.Names {
+				taken[id.Name] = true
+			}
+			if f.Tag != nil {
+				tag, _ := strconv.Unquote(f.Tag.Value)
+				jsonName, _, _ := strings.Cut(reflect.StructTag(tag).Get("json"), ",")
+				taken[jsonName] = true
+			}
+		}
+
+		var b strings.Builder
+		for i, f := range add {
+			if taken[f.Name] || taken[f.JSONName] {
+				return nil, fmt.Errorf("%s already has a field %s", name, f.JSONName)
+			}
+			if i > 0 || len(st.Fields.List) > 0 {
+				b.WriteString("\n")
+			}
+			for _, c := range f.Comments {
+				b.WriteString("\t// " + c + "\n")
+			}
+			fmt.Fprintf(&b, "\t%s %s `%s`\n", f.Name, f.Type, f.Tag())
+		}
+		inserts = append(inserts, insertion{at: fset.Position(st.Fields.Closing).Offset, text: b.String()})
+	}
+
+	// Insert from the end of the file, so earlier offsets stay valid.
+	sort.Slice(inserts, func(i, j int) bool { return inserts[i].at > inserts[j].at })
+	out := append([]byte{}, src...)
+	for _, in := range inserts {
+		text := in.text
+		if in.at > 0 && out[in.at-1] != '\n' {
+			text = "\n" + text // a struct closed on its last field's line, or struct{}
+		}
+		out = append(out[:in.at], append([]byte(text), out[in.at:]...)...)
+	}
+	return format.Source(out)
+}
+
+// findStruct returns the struct type declared as name in file.
+func findStruct(file *ast.File, name string) *ast.StructType {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if st, ok := ts.Type.(*ast.StructType); ok && ts.Name.Name == name {
+				return st
+			}
+		}
+	}
+	return nil
+}
+
+// addReferences adds the fields of the references declared in decls to model,
+// the fields of res, and returns them. res is nil for the ProviderConfig, which
+// takes none.
+func addReferences(cfg config.Config, res *resource.Resource, model *openapi.Model,
+	decls []string,
+) ([]openapi.Reference, error) {
+	refs := make([]openapi.Reference, 0, len(decls))
+	for _, decl := range decls {
+		ref, err := openapi.ParseReference(decl)
+		if err != nil {
+			return nil, err
+		}
+		typ, err := referenceType(cfg, *res, ref)
+		if err != nil {
+			return nil, err
+		}
+		if err := model.AddReference(ref, typ, res.API != nil && res.API.Namespaced); err != nil {
+			return nil, err
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+// referenceType returns the type ref names as the reference marker on a field
+// of res takes it. The type is the referenced kind at its storage version: a
+// reference resolves against the version the API server stores.
+func referenceType(cfg config.Config, res resource.Resource, ref openapi.Reference) (string, error) {
+	group := strings.TrimSuffix(ref.Group, "."+cfg.GetDomain())
+	if group == "" {
+		group = res.Group
+	}
+	all, err := cfg.GetResources()
+	if err != nil {
+		return "", fmt.Errorf("reading project resources: %w", err)
+	}
+	settings, err := core.LoadProjectSettings(cfg)
+	if err != nil {
+		return "", err
+	}
+	// res may be the kind create api is adding, which is not in PROJECT yet.
+	all = append(all, res)
+	version := ""
+	for _, r := range all {
+		if r.Group != group || r.Kind != ref.Kind {
+			continue
+		}
+		if hub := settings.StorageVersion(r); hub != "" {
+			version = hub
+			break
+		}
+		version = r.Version
+	}
+	if version == "" {
+		return "", fmt.Errorf("reference %s: kind %s is not in group %s of this project; create it first",
+			ref.Name, ref.Kind, group)
+	}
+	if group == res.Group && version == res.Version {
+		return ref.Kind, nil
+	}
+	return fmt.Sprintf("%s/apis/%s/%s.%s", cfg.GetRepository(), group, version, ref.Kind), nil
+}
+
+// forProviderLine is the spec.forProvider line of an example manifest, empty
+// or not.
+var forProviderLine = regexp.MustCompile(`(?m)^( *)forProvider:( *\{\})? *$`)
+
+// addExampleReferences returns the example manifest src with a Ref for each of
+// refs, to the object named example, at the top of spec.forProvider. A manifest
+// without a forProvider line of its own is returned as it is.
+func addExampleReferences(src []byte, refs []openapi.Reference) []byte {
+	loc := forProviderLine.FindSubmatchIndex(src)
+	if loc == nil {
+		return src
+	}
+	pad := strings.Repeat(" ", loc[3]-loc[2]+2)
+	var b strings.Builder
+	b.Write(src[loc[0]:loc[3]])
+	b.WriteString("forProvider:")
+	for _, ref := range refs {
+		fmt.Fprintf(&b, "\n%s%sRef:\n%s  name: example", pad, ref.Name, pad)
+	}
+	return append(append(append([]byte{}, src[:loc[0]]...), b.String()...), src[loc[1]:]...)
+}
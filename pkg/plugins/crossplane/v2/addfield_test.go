@@ -0,0 +1,159 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"sigs.k8s.io/kubebuilder/v4/pkg/model/resource"
+
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/openapi"
+)
+
+func TestAddFields(t *testing.T) {
+	cfg, disk := deleteTestProject(t)
+	target, err := fieldTarget(cfg, addFieldOptions{kind: kindBucket, params: []string{"region:string"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := afero.ReadFile(disk, target.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A user's edit the insertion must keep.
+	src = []byte(strings.Replace(string(src), "// TODO: Add your configurable fields here", "// Kept as written.", 1))
+
+	m, err := openapi.Declare(kindBucket, []string{"region:string,required"}, []string{"arn:string"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := addFields(src, map[string][]openapi.Field{target.spec: m.Parameters, target.status: m.Observation})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"\t// Kept as written.\n\tConfigurableField string `json:\"configurableField\"`\n\n" +
+			"\t// +kubebuilder:validation:Required\n\tRegion string `json:\"region\"`\n}",
+		"\tStatus            string `json:\"status,omitempty\"`\n\n\t// +optional\n\tARN *string `json:\"arn,omitempty\"`\n}",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("types file does not contain %q:\n%s", want, out)
+		}
+	}
+
+	if _, err := addFields(out, map[string][]openapi.Field{target.spec: m.Parameters}); err == nil ||
+		!strings.Contains(err.Error(), "already has a field region") {
+		t.Errorf("adding region twice: error = %v", err)
+	}
+	if _, err := addFields(out, map[string][]openapi.Field{"NoSuchParameters": m.Parameters}); err == nil {
+		t.Error("adding to a missing struct succeeded")
+	}
+
+	// An empty struct gains the field on a line of its own.
+	empty, err := addFields([]byte("package p\n\ntype Spec struct{}\n"), map[string][]openapi.Field{"Spec": m.Parameters})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(empty), "type Spec struct {\n\t// +kubebuilder:validation:Required\n\tRegion string") {
+		t.Errorf("empty struct:\n%s", empty)
+	}
+}
+
+func TestFieldTarget(t *testing.T) {
+	cfg, _ := deleteTestProject(t)
+	target, err := fieldTarget(cfg, addFieldOptions{kind: "ProviderConfig", params: []string{"endpoint:string"}})
+	if err != nil || target.path != providerConfigTypes || target.spec != "ProviderConfigSpec" {
+		t.Errorf("ProviderConfig target = %+v, %v", target, err)
+	}
+	if _, err := fieldTarget(cfg, addFieldOptions{kind: "ProviderConfig", observe: []string{"ready:bool"}}); err == nil {
+		t.Error("an observed ProviderConfig field was accepted")
+	}
+	if _, err := fieldTarget(cfg, addFieldOptions{kind: "Nope"}); err == nil {
+		t.Error("a kind outside the project was accepted")
+	}
+
+	multi := multiVersionProject(t)
+	if _, err := fieldTarget(multi, addFieldOptions{kind: kindBucket}); err == nil ||
+		!strings.Contains(err.Error(), "pick one with --version") {
+		t.Errorf("multi-version kind without --version: error = %v", err)
+	}
+	target, err = fieldTarget(multi, addFieldOptions{kind: kindBucket, version: "v1alpha1"})
+	if err != nil || target.path != "apis/storage/v1alpha1/bucket_types.go" || target.status != "BucketObservation" {
+		t.Errorf("v1alpha1 target = %+v, %v", target, err)
+	}
+}
+
+func TestReferenceType(t *testing.T) {
+	cfg, _ := deleteTestProject(t)
+	all, _ := cfg.GetResources()
+	var bucket, instance resource.Resource
+	for _, r := range all {
+		switch r.Kind {
+		case kindBucket:
+			bucket = r
+		case "Instance":
+			instance = r
+		}
+	}
+	for _, tc := range []struct {
+		from resource.Resource
+		decl string
+		want string
+	}{
+		{instance, "diskId=Disk", "Disk"},
+		{bucket, "diskId=Disk.compute", "github.com/example/provider-test/apis/compute/v1alpha1.Disk"},
+		{bucket, "diskId=Disk.compute.example.com", "github.com/example/provider-test/apis/compute/v1alpha1.Disk"},
+	} {
+		ref, err := openapi.ParseReference(tc.decl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := referenceType(cfg, tc.from, ref); err != nil || got != tc.want {
+			t.Errorf("%s from %s = %q, %v; want %q", tc.decl, tc.from.Kind, got, err, tc.want)
+		}
+	}
+	ref, _ := openapi.ParseReference("diskId=Disk")
+	if _, err := referenceType(cfg, bucket, ref); err == nil || !strings.Contains(err.Error(), "not in group storage") {
+		t.Errorf("Disk from storage: error = %v", err)
+	}
+
+	multi := multiVersionProject(t)
+	all, _ = multi.GetResources()
+	ref, _ = openapi.ParseReference("parentId=Bucket")
+	for _, r := range all {
+		if r.Kind == kindBucket && r.Version == "v1alpha1" {
+			if got, err := referenceType(multi, r, ref); err != nil || !strings.HasSuffix(got, "/v1beta1.Bucket") {
+				t.Errorf("reference to a multi-version kind = %q, %v; want its storage version", got, err)
+			}
+		}
+	}
+}
+
+func TestAddExampleReferences(t *testing.T) {
+	refs := []openapi.Reference{{Name: "networkId", Kind: "Network"}}
+	for _, tc := range []struct{ in, want string }{
+		{"spec:\n  forProvider:\n    size: 1\n", "spec:\n  forProvider:\n    networkIdRef:\n      name: example\n    size: 1\n"},
+		{"spec:\n  forProvider: {}\n", "spec:\n  forProvider:\n    networkIdRef:\n      name: example\n"},
+		{"spec: {}\n", "spec: {}\n"},
+	} {
+		if got := string(addExampleReferences([]byte(tc.in), refs)); got != tc.want {
+			t.Errorf("addExampleReferences(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
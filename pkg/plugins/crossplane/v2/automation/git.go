@@ -18,6 +18,7 @@ package automation
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"strings"
 
@@ -119,7 +120,10 @@ func (g *GitOperations) headIsScaffold(ctx context.Context) bool {
 	return strings.Contains(out, ScaffoldCommitTrailer)
 }
 
-func (g *GitOperations) AddSubmodule(ctx context.Context, url, path string) error {
+// AddSubmodule adds the submodule at path, checked out at ref — a tag, branch
+// or commit — or at the remote's default branch when ref is empty. A submodule
+// that is already there is only initialized.
+func (g *GitOperations) AddSubmodule(ctx context.Context, url, ref, path string) error {
 	if _, err := os.Stat(path); err == nil {
 		// Directory exists, check if it's a submodule
 		if _, err := os.Stat(path + "/.git"); err == nil {
@@ -133,6 +137,16 @@ func (g *GitOperations) AddSubmodule(ctx context.Context, url, path string) erro
 	if err := g.runner.AddSubmodule(ctx, url, path); err != nil {
 		return err
 	}
+	if ref != "" {
+		// Stage the pinned commit, or the update below would check out the
+		// one `submodule add` recorded.
+		if err := core.NewGitCommandRunner(path).RunCommand(ctx, "checkout", "--quiet", ref); err != nil {
+			return fmt.Errorf("checking out %s at %s: %w", path, ref, err)
+		}
+		if err := g.runner.Add(ctx, path); err != nil {
+			return err
+		}
+	}
 
 	// Initialize the submodule
 	return g.runner.RunCommand(ctx, "submodule", "update", "--init", "--recursive")
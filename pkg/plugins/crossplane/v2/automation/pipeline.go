@@ -18,6 +18,7 @@ package automation
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/core"
 )
@@ -47,11 +48,94 @@ Scaffolded Crossplane provider project for %s
 	}
 }
 
-func NewAPICommitPipeline(config *core.PluginConfig, resourceKind string) *Pipeline {
+// NewAPICommitPipeline generates code for a new kind and commits it; scripts
+// are the kind's scaffolded scripts, made executable first.
+func NewAPICommitPipeline(config *core.PluginConfig, resourceKind string, scripts ...string) *Pipeline {
 	commitMessage := fmt.Sprintf(`Add %s managed resource
 
 Scaffolded CRD, controller, and client code for %s resource`, resourceKind, resourceKind)
 
+	var steps []Step
+	if len(scripts) > 0 {
+		steps = append(steps, NewExecutableBitStep(scripts...))
+	}
+	return &Pipeline{
+		steps: append(steps,
+			NewMakeStep("generate"),
+			NewGitFoldCommitStep(config, commitMessage),
+		),
+	}
+}
+
+// NewAPIDeletePipeline regenerates code after a kind is removed and records the
+// removal the same way create api records an addition.
+func NewAPIDeletePipeline(config *core.PluginConfig, resourceKind string) *Pipeline {
+	commitMessage := fmt.Sprintf(`Remove %s managed resource
+
+Removed CRD, controller, and client code for %s resource`, resourceKind, resourceKind)
+
+	return &Pipeline{
+		steps: []Step{
+			NewMakeStep("generate"),
+			NewGitFoldCommitStep(config, commitMessage),
+		},
+	}
+}
+
+// NewAPIVersionPipeline regenerates code after a kind gains an API version and
+// records it the same way create api records a new kind.
+func NewAPIVersionPipeline(config *core.PluginConfig, resourceKind, version string) *Pipeline {
+	commitMessage := fmt.Sprintf(`Add %s %s API version
+
+Scaffolded %s types and conversion code for %s resource`, version, resourceKind, version, resourceKind)
+
+	return &Pipeline{
+		steps: []Step{
+			NewMakeStep("generate"),
+			NewGitFoldCommitStep(config, commitMessage),
+		},
+	}
+}
+
+// NewWebhookCommitPipeline regenerates code, webhook manifests included, after
+// create webhook and records it the same way create api records a new kind.
+func NewWebhookCommitPipeline(config *core.PluginConfig, resourceKind string) *Pipeline {
+	commitMessage := fmt.Sprintf(`Add %s admission webhooks
+
+Scaffolded webhook registration and admission functions for %s resource`, resourceKind, resourceKind)
+
+	return &Pipeline{
+		steps: []Step{
+			NewMakeStep("generate"),
+			NewGitFoldCommitStep(config, commitMessage),
+		},
+	}
+}
+
+// NewEditPipeline tidies and regenerates after edit moves the provider to a new
+// module path or domain, and records the move the same way create api records
+// a new kind.
+func NewEditPipeline(config *core.PluginConfig, change string) *Pipeline {
+	commitMessage := fmt.Sprintf(`Change provider %s
+
+Rewrote import paths, API group references and generated code`, change)
+
+	return &Pipeline{
+		steps: []Step{
+			NewGoModTidyStep(),
+			NewMakeStep("generate"),
+			NewGitFoldCommitStep(config, commitMessage),
+		},
+	}
+}
+
+// NewAddFieldPipeline regenerates code after add field changes a struct and
+// records the change the same way create api records a new kind.
+func NewAddFieldPipeline(config *core.PluginConfig, structName string, fields []string) *Pipeline {
+	commitMessage := fmt.Sprintf(`Add %s to %s
+
+Added the fields and regenerated the deepcopy functions and CRDs`, strings.Join(fields, ", "), structName)
+
 	return &Pipeline{
 		steps: []Step{
 			NewMakeStep("generate"),
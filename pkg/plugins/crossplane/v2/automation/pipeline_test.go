@@ -80,6 +80,47 @@ func TestNewAPICommitPipeline_CommitsLast(t *testing.T) {
 	})
 }
 
+func TestNewAPIDeletePipeline_CommitsLast(t *testing.T) {
+	cfg := core.NewPluginConfig("crossplane")
+	p := NewAPIDeletePipeline(cfg, "Bucket")
+
+	assertStepOrder(t, p, []string{
+		"Run make generate",
+		"Commit changes (fold into initial scaffold if applicable)",
+	})
+}
+
+func TestNewAPIVersionPipeline_CommitsLast(t *testing.T) {
+	cfg := core.NewPluginConfig("crossplane")
+	p := NewAPIVersionPipeline(cfg, "Bucket", "v1beta1")
+
+	assertStepOrder(t, p, []string{
+		"Run make generate",
+		"Commit changes (fold into initial scaffold if applicable)",
+	})
+}
+
+func TestNewWebhookCommitPipeline_CommitsLast(t *testing.T) {
+	cfg := core.NewPluginConfig("crossplane")
+	p := NewWebhookCommitPipeline(cfg, "Bucket")
+
+	assertStepOrder(t, p, []string{
+		"Run make generate",
+		"Commit changes (fold into initial scaffold if applicable)",
+	})
+}
+
+func TestNewEditPipeline_CommitsLast(t *testing.T) {
+	cfg := core.NewPluginConfig("crossplane")
+	p := NewEditPipeline(cfg, "domain to acme.io")
+
+	assertStepOrder(t, p, []string{
+		"Download dependencies (go mod tidy)",
+		"Run make generate",
+		"Commit changes (fold into initial scaffold if applicable)",
+	})
+}
+
 func TestPipeline_Run_AbortsOnFirstFailure(t *testing.T) {
 	firstRan, secondRan := false, false
 	wantErr := errors.New("boom")
@@ -99,6 +99,7 @@ func (s *GitFoldCommitStep) Execute() error {
 type GitSubmoduleStep struct {
 	git  *GitOperations
 	url  string
+	ref  string
 	path string
 }
 
@@ -106,6 +107,7 @@ func NewGitSubmoduleStep(config *core.PluginConfig) *GitSubmoduleStep {
 	return &GitSubmoduleStep{
 		git:  NewGitOperations(config),
 		url:  config.Git.BuildSubmoduleURL,
+		ref:  config.Git.BuildSubmoduleRef,
 		path: "build",
 	}
 }
@@ -115,7 +117,7 @@ func (s *GitSubmoduleStep) Name() string {
 }
 
 func (s *GitSubmoduleStep) Execute() error {
-	return s.git.AddSubmodule(context.Background(), s.url, s.path)
+	return s.git.AddSubmodule(context.Background(), s.url, s.ref, s.path)
 }
 
 type MakeStep struct {
@@ -17,6 +17,8 @@ limitations under the License.
 package v2
 
 import (
+	"sigs.k8s.io/kubebuilder/v4/pkg/config"
+
 	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/core"
 )
 
@@ -29,3 +31,23 @@ type (
 func NewPluginConfig() *PluginConfig {
 	return core.NewPluginConfig(pluginName)
 }
+
+// loadPluginConfig returns the settings a command runs with: the built-in
+// defaults, the user config file and XP_PROVIDER_GEN_* environment variables,
+// and then, for an existing project, the settings PROJECT recorded at `init`.
+// cfg is nil before there is a project.
+func loadPluginConfig(cfg config.Config) (*PluginConfig, error) {
+	c, err := core.LoadPluginConfig(pluginName)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return c, nil
+	}
+	settings, err := core.LoadProjectSettings(cfg)
+	if err != nil {
+		return nil, err
+	}
+	c.ApplyProject(settings)
+	return c, nil
+}
@@ -17,47 +17,206 @@ limitations under the License.
 package core
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+
+	"sigs.k8s.io/yaml"
 )
 
 type PluginConfig struct {
-	Name     string
-	Version  string
-	Defaults DefaultValues
-	Git      GitConfig
+	Name    string
+	Version string
+	GeneratorSettings
+}
+
+// GeneratorSettings are the options the generator runs with. The built-in
+// defaults are overridden by the user config file, then by XP_PROVIDER_GEN_*
+// environment variables (see LoadPluginConfig). `init` records the result in
+// PROJECT, and later commands run with PROJECT's copy (see ApplyProject).
+type GeneratorSettings struct {
+	Defaults DefaultValues `json:"defaults,omitzero"`
+	Git      GitConfig     `json:"git,omitzero"`
 }
 
 type DefaultValues struct {
-	Domain     string
-	RepoPrefix string
-	Force      bool
+	// Domain is the API group domain `init` uses without --domain.
+	Domain string `json:"domain,omitempty"`
+
+	// RepoPrefix is where `init` without --repo puts the module:
+	// <prefix>/provider-<directory>.
+	RepoPrefix string `json:"repoPrefix,omitempty"`
+
+	// RegistryOrgs are the package registries the Makefile publishes to.
+	RegistryOrgs []string `json:"registryOrgs,omitempty"`
+
+	// ClusterProviderConfig makes `init` behave as if given
+	// --cluster-provider-config. Configuration can only turn it on;
+	// --cluster-provider-config=false turns it off for one project.
+	ClusterProviderConfig bool `json:"clusterProviderConfig,omitempty"`
+
+	// License and CopyrightOwner are what `init` uses without --license and
+	// --copyright-owner.
+	License        string `json:"license,omitempty"`
+	CopyrightOwner string `json:"copyrightOwner,omitempty"`
+
+	// Force is the default of `create api --force`. It is a habit of one
+	// person rather than a fact about the project, so it is never recorded.
+	Force bool `json:"-"`
 }
 
 type GitConfig struct {
-	BuildSubmoduleURL string
-	Author            string
-	Email             string
+	BuildSubmoduleURL string `json:"buildSubmoduleURL,omitempty"`
+
+	// BuildSubmoduleRef is the tag, branch or commit the build submodule is
+	// checked out at; empty means the remote's default branch.
+	BuildSubmoduleRef string `json:"buildSubmoduleRef,omitempty"`
+
+	// Author and Email are the identity `init` commits with when neither
+	// --git-name/--git-email nor git's own config supplies one.
+	Author string `json:"author,omitempty"`
+	Email  string `json:"email,omitempty"`
 }
 
+// DefaultRegistryOrgs are the registries a provider publishes to unless
+// configured otherwise.
+var DefaultRegistryOrgs = []string{"xpkg.upbound.io/crossplane"}
+
 func NewPluginConfig(pluginName string) *PluginConfig {
 	return &PluginConfig{
 		Name:    pluginName,
 		Version: "v1.0.0",
-
-		Defaults: DefaultValues{
-			Domain:     "",
-			RepoPrefix: "github.com/crossplane-contrib",
-			Force:      false,
+		GeneratorSettings: GeneratorSettings{
+			Defaults: DefaultValues{
+				Domain:         "",
+				RepoPrefix:     "github.com/crossplane-contrib",
+				RegistryOrgs:   DefaultRegistryOrgs,
+				License:        LicenseApache,
+				CopyrightOwner: legacyCopyrightOwner,
+				Force:          false,
+			},
+			Git: GitConfig{
+				BuildSubmoduleURL: "https://github.com/crossplane/build",
+				Author:            "Crossplane Provider Generator",
+				Email:             "noreply@crossplane.io",
+			},
 		},
+	}
+}
 
-		Git: GitConfig{
-			BuildSubmoduleURL: "https://github.com/crossplane/build",
-			Author:            "Crossplane Provider Generator",
-			Email:             "noreply@crossplane.io",
-		},
+// EnvPrefix starts the name of every environment variable the generator reads.
+const EnvPrefix = "XP_PROVIDER_GEN_"
+
+// UserConfigPath returns the user config file: $XP_PROVIDER_GEN_CONFIG, or
+// xp-provider-gen/config.yaml in the user's config directory
+// ($XDG_CONFIG_HOME, ~/.config, or the platform's equivalent).
+func UserConfigPath() (string, error) {
+	if path := os.Getenv(EnvPrefix + "CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "xp-provider-gen", "config.yaml"), nil
+}
+
+// LoadPluginConfig returns the built-in defaults overridden by the user config
+// file, which has the shape of GeneratorSettings, and then by the environment.
+// A missing file is not an error; one that does not parse, or names an unknown
+// field, is.
+func LoadPluginConfig(pluginName string) (*PluginConfig, error) {
+	c := NewPluginConfig(pluginName)
+
+	path, err := UserConfigPath()
+	if err != nil {
+		return nil, fmt.Errorf("locating the user config file: %w", err)
+	}
+	b, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	default:
+		var file GeneratorSettings
+		if err := yaml.UnmarshalStrict(b, &file); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+		c.merge(file)
+	}
+
+	env, err := settingsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	c.merge(env)
+	return c, nil
+}
+
+// settingsFromEnv reads the XP_PROVIDER_GEN_* variables. REGISTRY_ORGS is a
+// comma-separated list.
+func settingsFromEnv() (GeneratorSettings, error) {
+	var s GeneratorSettings
+	for name, field := range map[string]*string{
+		"DOMAIN":              &s.Defaults.Domain,
+		"REPO_PREFIX":         &s.Defaults.RepoPrefix,
+		"LICENSE":             &s.Defaults.License,
+		"COPYRIGHT_OWNER":     &s.Defaults.CopyrightOwner,
+		"BUILD_SUBMODULE_URL": &s.Git.BuildSubmoduleURL,
+		"BUILD_SUBMODULE_REF": &s.Git.BuildSubmoduleRef,
+		"GIT_AUTHOR":          &s.Git.Author,
+		"GIT_EMAIL":           &s.Git.Email,
+	} {
+		*field = strings.TrimSpace(os.Getenv(EnvPrefix + name))
+	}
+	for _, org := range strings.Split(os.Getenv(EnvPrefix+"REGISTRY_ORGS"), ",") {
+		if org = strings.TrimSpace(org); org != "" {
+			s.Defaults.RegistryOrgs = append(s.Defaults.RegistryOrgs, org)
+		}
+	}
+	if v := os.Getenv(EnvPrefix + "CLUSTER_PROVIDER_CONFIG"); v != "" {
+		on, err := strconv.ParseBool(v)
+		if err != nil {
+			return s, fmt.Errorf("%sCLUSTER_PROVIDER_CONFIG=%q is not a boolean", EnvPrefix, v)
+		}
+		s.Defaults.ClusterProviderConfig = on
+	}
+	return s, nil
+}
+
+// ApplyProject replaces c's settings with those recorded in PROJECT, so a
+// command on an existing project runs with the options it was created with
+// rather than whatever this machine is configured with. A project made
+// before they were recorded keeps c's.
+func (c *PluginConfig) ApplyProject(s ProjectSettings) {
+	c.merge(s.Generator)
+}
+
+// merge overrides c's settings with every one o sets.
+func (c *PluginConfig) merge(o GeneratorSettings) {
+	for dst, src := range map[*string]string{
+		&c.Defaults.Domain:         o.Defaults.Domain,
+		&c.Defaults.RepoPrefix:     o.Defaults.RepoPrefix,
+		&c.Defaults.License:        o.Defaults.License,
+		&c.Defaults.CopyrightOwner: o.Defaults.CopyrightOwner,
+		&c.Git.BuildSubmoduleURL:   o.Git.BuildSubmoduleURL,
+		&c.Git.BuildSubmoduleRef:   o.Git.BuildSubmoduleRef,
+		&c.Git.Author:              o.Git.Author,
+		&c.Git.Email:               o.Git.Email,
+	} {
+		if src != "" {
+			*dst = src
+		}
+	}
+	if len(o.Defaults.RegistryOrgs) > 0 {
+		c.Defaults.RegistryOrgs = o.Defaults.RegistryOrgs
+	}
+	if o.Defaults.ClusterProviderConfig {
+		c.Defaults.ClusterProviderConfig = true
 	}
 }
 
@@ -0,0 +1,85 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLoadPluginConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv("XP_PROVIDER_GEN_CONFIG", path)
+
+	c, err := LoadPluginConfig("test")
+	if err != nil {
+		t.Fatalf("without a config file: %v", err)
+	}
+	if c.Defaults.RepoPrefix != "github.com/crossplane-contrib" {
+		t.Errorf("without a config file, RepoPrefix = %q, want the built-in default", c.Defaults.RepoPrefix)
+	}
+
+	_ = os.WriteFile(path, []byte(`defaults:
+  domain: acme.io
+  repoPrefix: github.com/acme
+  registryOrgs: [xpkg.acme.io/acme]
+git:
+  buildSubmoduleURL: https://git.acme.io/build
+  author: Acme Bot
+`), 0o644)
+	t.Setenv("XP_PROVIDER_GEN_DOMAIN", "example.org")
+	t.Setenv("XP_PROVIDER_GEN_REGISTRY_ORGS", "xpkg.acme.io/acme, ghcr.io/acme")
+	t.Setenv("XP_PROVIDER_GEN_CLUSTER_PROVIDER_CONFIG", "true")
+
+	c, err = LoadPluginConfig("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Defaults.Domain != "example.org" {
+		t.Errorf("Domain = %q, want the environment over the file", c.Defaults.Domain)
+	}
+	if c.Defaults.RepoPrefix != "github.com/acme" || c.Git.Author != "Acme Bot" {
+		t.Errorf("settings = %+v, want the file's", c.GeneratorSettings)
+	}
+	if c.Git.Email != "noreply@crossplane.io" {
+		t.Errorf("Email = %q, want the built-in default the file leaves alone", c.Git.Email)
+	}
+	if want := []string{"xpkg.acme.io/acme", "ghcr.io/acme"}; !slices.Equal(c.Defaults.RegistryOrgs, want) {
+		t.Errorf("RegistryOrgs = %v, want %v", c.Defaults.RegistryOrgs, want)
+	}
+	if !c.Defaults.ClusterProviderConfig {
+		t.Error("ClusterProviderConfig is off, want it on from the environment")
+	}
+
+	// What PROJECT recorded wins over this machine's configuration.
+	c.ApplyProject(ProjectSettings{Generator: GeneratorSettings{
+		Git: GitConfig{BuildSubmoduleURL: "https://github.com/crossplane/build", BuildSubmoduleRef: "v0.1.0"},
+	}})
+	if c.Git.BuildSubmoduleURL != "https://github.com/crossplane/build" || c.Git.BuildSubmoduleRef != "v0.1.0" {
+		t.Errorf("after ApplyProject, Git = %+v, want PROJECT's submodule", c.Git)
+	}
+	if c.Git.Author != "Acme Bot" {
+		t.Errorf("after ApplyProject, Author = %q, want what PROJECT leaves unset kept", c.Git.Author)
+	}
+
+	_ = os.WriteFile(path, []byte("defaults:\n  registryOrg: xpkg.acme.io/acme\n"), 0o644)
+	if _, err := LoadPluginConfig("test"); err == nil {
+		t.Error("a misspelled field in the config file was accepted")
+	}
+}
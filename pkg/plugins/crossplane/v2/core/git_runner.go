@@ -110,6 +110,21 @@ func (g *GitCommandRunner) CommitWithAuthor(ctx context.Context, message, author
 	return g.RunCommandWithStdin(ctx, message, "commit", "-F", "-", authorFlag)
 }
 
+// Head returns the commit HEAD points at.
+func (g *GitCommandRunner) Head(ctx context.Context) (string, error) {
+	return g.RunCommandWithOutput(ctx, "rev-parse", "--verify", "HEAD")
+}
+
+// Restore puts the working tree back to commit: tracked files are reset and
+// untracked ones removed. Ignored files are left alone, so build outputs and
+// caches survive. It is only exact for a tree that was clean at commit.
+func (g *GitCommandRunner) Restore(ctx context.Context, commit string) error {
+	if err := g.RunCommand(ctx, "reset", "--hard", "--quiet", commit); err != nil {
+		return err
+	}
+	return g.RunCommand(ctx, "clean", "-d", "--force", "--quiet")
+}
+
 // AddSubmodule adds a git submodule.
 func (g *GitCommandRunner) AddSubmodule(ctx context.Context, url, path string) error {
 	return g.RunCommand(ctx, "submodule", "add", url, path)
@@ -0,0 +1,102 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"slices"
+	"strings"
+)
+
+// The licenses `init --license` offers.
+const (
+	LicenseApache      = "apache-2.0"
+	LicenseMIT         = "mit"
+	LicenseProprietary = "proprietary"
+)
+
+// Licenses lists the license IDs `init --license` accepts.
+var Licenses = []string{LicenseApache, LicenseMIT, LicenseProprietary}
+
+// Before `init` recorded a license, every provider was generated under this
+// one; a project without a recorded license keeps it, so `update` does not
+// rewrite every header.
+const (
+	legacyCopyrightOwner = "The Crossplane Authors"
+	legacyCopyrightYear  = 2025
+)
+
+// License is how the provider is licensed: it decides the LICENSE file, the
+// package's license annotation, and the header of every generated Go file.
+type License struct {
+	// ID is one of Licenses.
+	ID string `json:"id,omitempty"`
+
+	Owner string `json:"owner,omitempty"`
+	Year  int    `json:"year,omitempty"`
+
+	// Boilerplate is the Go file header from `init --boilerplate-file`, used
+	// in place of the one ID implies. It must be a Go comment.
+	Boilerplate string `json:"boilerplate,omitempty"`
+}
+
+// WithDefaults fills in what l leaves unset with the license providers were
+// generated under before it was recorded.
+func (l License) WithDefaults() License {
+	if l.ID == "" {
+		l.ID = LicenseApache
+	}
+	if l.Owner == "" {
+		l.Owner = legacyCopyrightOwner
+	}
+	if l.Year == 0 {
+		l.Year = legacyCopyrightYear
+	}
+	return l
+}
+
+// SPDX returns the license's SPDX identifier, as the package metadata and
+// hack/boilerplate.go.txt name it.
+func (l License) SPDX() string {
+	switch l.ID {
+	case LicenseMIT:
+		return "MIT"
+	case LicenseProprietary:
+		return "LicenseRef-Proprietary"
+	default:
+		return "Apache-2.0"
+	}
+}
+
+// Validate reports an unknown license ID, or a boilerplate that is not a Go
+// comment and so would not compile at the top of a file.
+func (l License) Validate() error {
+	if l.ID != "" && !slices.Contains(Licenses, l.ID) {
+		return fmt.Errorf("unknown license %q; use one of %s", l.ID, strings.Join(Licenses, ", "))
+	}
+	if l.Boilerplate == "" {
+		return nil
+	}
+	file, err := parser.ParseFile(token.NewFileSet(), "", l.Boilerplate+"\n\npackage p\n",
+		parser.PackageClauseOnly|parser.ParseComments)
+	if err != nil || len(file.Comments) == 0 {
+		return fmt.Errorf("boilerplate must be a Go comment, such as a /* ... */ block or // lines")
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// LockFile records the SHA-256 of every tool-owned file as the generator last
+// wrote it. The header says a file may be overwritten; the lock says whether
+// anyone has edited it since, which the header alone cannot. It uses the
+// `sha256sum` format, so `sha256sum -c .xp-provider-gen.lock` checks it too.
+const LockFile = ".xp-provider-gen.lock"
+
+// Lock maps a slash-separated project path to the hex SHA-256 of its content.
+type Lock map[string]string
+
+// ReadLock reads the lock file from fs. A project without one — made before
+// the lock existed — gets an empty lock, against which nothing has drifted.
+func ReadLock(fs afero.Fs) (Lock, error) {
+	lock := Lock{}
+	exists, err := afero.Exists(fs, LockFile)
+	if err != nil || !exists {
+		return lock, err
+	}
+	data, err := afero.ReadFile(fs, LockFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", LockFile, err)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		sum, path, ok := strings.Cut(scanner.Text(), "  ")
+		if !ok || len(sum) != sha256.Size*2 || path == "" {
+			return nil, fmt.Errorf("%s:%d: want \"<sha256>  <path>\"", LockFile, line)
+		}
+		lock[path] = sum
+	}
+	return lock, scanner.Err()
+}
+
+// Write saves the lock to fs, dropping entries for files that no longer exist
+// there, sorted by path so it diffs cleanly.
+func (l Lock) Write(fs afero.Fs) error {
+	paths := make([]string, 0, len(l))
+	for path := range l {
+		if exists, err := afero.Exists(fs, path); err != nil {
+			return err
+		} else if exists {
+			paths = append(paths, path)
+		}
+	}
+	slices.Sort(paths)
+
+	var b strings.Builder
+	for _, path := range paths {
+		fmt.Fprintf(&b, "%s  %s\n", l[path], path)
+	}
+	return afero.WriteFile(fs, LockFile, []byte(b.String()), 0o644)
+}
+
+// Record notes that the generator wrote content to path. Only tool-owned files
+// are locked; a user-owned one drops any entry it had.
+func (l Lock) Record(path string, content []byte) {
+	if !IsToolOwned(content) {
+		delete(l, path)
+		return
+	}
+	l[path] = hashContent(content)
+}
+
+// Drifted reports whether content differs from what the generator last wrote to
+// path. A path the lock has no entry for has not drifted.
+func (l Lock) Drifted(path string, content []byte) bool {
+	sum, ok := l[path]
+	return ok && sum != hashContent(content)
+}
+
+func hashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
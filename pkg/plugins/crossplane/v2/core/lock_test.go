@@ -0,0 +1,66 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestLock(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	wiring := []byte(GeneratedHeader + "\npackage bucket\n")
+	external := []byte("package bucket\n")
+	_ = afero.WriteFile(fs, "internal/controller/bucket/wiring.go", wiring, 0o644)
+	_ = afero.WriteFile(fs, "internal/controller/bucket/external.go", external, 0o644)
+
+	lock, err := ReadLock(fs)
+	if err != nil || len(lock) != 0 {
+		t.Fatalf("ReadLock() without a lock file = %v, %v; want an empty lock", lock, err)
+	}
+	lock.Record("internal/controller/bucket/wiring.go", wiring)
+	lock.Record("internal/controller/bucket/external.go", external)
+	lock.Record("apis/register.go", wiring) // written, then deleted
+	if err := lock.Write(fs); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := afero.ReadFile(fs, LockFile)
+	if got := strings.Count(string(data), "\n"); got != 1 {
+		t.Errorf("lock file has %d entries, want only wiring.go:\n%s", got, data)
+	}
+	lock, err = ReadLock(fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lock.Drifted("internal/controller/bucket/wiring.go", wiring) {
+		t.Error("unchanged wiring.go reported as drifted")
+	}
+	if !lock.Drifted("internal/controller/bucket/wiring.go", append(wiring, "// edited\n"...)) {
+		t.Error("edited wiring.go not reported as drifted")
+	}
+	if lock.Drifted("internal/controller/bucket/external.go", []byte("package bucket\n// mine\n")) {
+		t.Error("a user-owned file has no entry and cannot drift")
+	}
+
+	_ = afero.WriteFile(fs, LockFile, []byte("not a lock\n"), 0o644)
+	if _, err := ReadLock(fs); err == nil {
+		t.Error("ReadLock() accepted a malformed lock file")
+	}
+}
@@ -24,23 +24,42 @@ import "bytes"
 // (golangci-lint, etc.) also recognize these files as generated.
 const GeneratedHeader = "// Code generated by xp-provider-gen. DO NOT EDIT."
 
-// headerScanLimit bounds how far into a file we look for the header (it always
-// appears near the top, after the license boilerplate). It must comfortably
-// exceed the boilerplate length plus the header offset; the current Apache
-// boilerplate is ~570 bytes, so 1024 leaves ample room. If the boilerplate ever
-// grows past this, tool-owned files would be misclassified as user-owned (stale
-// but never destructive).
+// headerScanLimit bounds how far past the license boilerplate we look for the
+// header: it always follows the boilerplate closely, and the limit keeps a
+// mention of it deeper in a user's file from counting.
 const headerScanLimit = 1024
 
 // IsToolOwned reports whether content carries the generated header, i.e. the
-// tool owns the file and may overwrite it.
+// tool owns the file and may overwrite it. The boilerplate a file opens with
+// is skipped before the limit applies, however long a custom one is.
 func IsToolOwned(content []byte) bool {
-	if len(content) > headerScanLimit {
-		content = content[:headerScanLimit]
+	if limit := leadingCommentLen(content) + headerScanLimit; len(content) > limit {
+		content = content[:limit]
 	}
 	return bytes.Contains(content, []byte(GeneratedHeader))
 }
 
+// leadingCommentLen returns the length of the Go comment content opens with: a
+// /* */ block, or a run of // lines. The header is a // line of its own, so a
+// run ends at the first blank line or at the header.
+func leadingCommentLen(content []byte) int {
+	rest := bytes.TrimLeft(content, " \t\r\n")
+	if after, ok := bytes.CutPrefix(rest, []byte("/*")); ok {
+		end := bytes.Index(after, []byte("*/"))
+		if end < 0 {
+			return 0
+		}
+		return len(content) - len(after) + end + len("*/")
+	}
+	n := len(content) - len(rest)
+	for bytes.HasPrefix(rest, []byte("//")) && !bytes.HasPrefix(rest, []byte(GeneratedHeader)) {
+		line, next, _ := bytes.Cut(rest, []byte("\n"))
+		n += len(line) + 1
+		rest = next
+	}
+	return n
+}
+
 // WriteDecision is what an update should do with a single target file.
 type WriteDecision int
 
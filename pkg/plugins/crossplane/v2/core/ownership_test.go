@@ -16,7 +16,10 @@ limitations under the License.
 
 package core
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestIsToolOwned(t *testing.T) {
 	toolFile := "/*\nCopyright\n*/\n\n" + GeneratedHeader + "\n\npackage foo\n"
@@ -28,6 +31,21 @@ func TestIsToolOwned(t *testing.T) {
 	if IsToolOwned([]byte(userFile)) {
 		t.Error("file without the header should be user-owned")
 	}
+
+	// A custom boilerplate may be far longer than the scan limit.
+	notice := strings.Repeat("All rights reserved by a very long notice.\n", 40)
+	for name, boilerplate := range map[string]string{
+		"block": "/*\n" + notice + "*/",
+		"lines": "// " + strings.ReplaceAll(strings.TrimSuffix(notice, "\n"), "\n", "\n// "),
+	} {
+		if !IsToolOwned([]byte(boilerplate + "\n\n" + GeneratedHeader + "\n\npackage foo\n")) {
+			t.Errorf("file with a long %s boilerplate and the header should be tool-owned", name)
+		}
+	}
+	deep := "package foo\n\n" + strings.Repeat("// filler\n", headerScanLimit/10) + GeneratedHeader + "\n"
+	if IsToolOwned([]byte(deep)) {
+		t.Error("a header mentioned deep in the body should not make the file tool-owned")
+	}
 }
 
 func TestDecideWrite(t *testing.T) {
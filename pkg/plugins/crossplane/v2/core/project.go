@@ -21,6 +21,7 @@ import (
 	"os"
 
 	"sigs.k8s.io/kubebuilder/v4/pkg/config"
+	cfgv3 "sigs.k8s.io/kubebuilder/v4/pkg/config/v3"
 	"sigs.k8s.io/kubebuilder/v4/pkg/model/resource"
 )
 
@@ -52,3 +53,26 @@ func (p *ProjectFile) AddResource(res resource.Resource) error {
 
 	return p.Save()
 }
+
+// RemoveResource drops the resource with the given GVK and saves PROJECT.
+// Kubebuilder's config interface can add resources but not remove them, so this
+// edits the v3 model directly — the only project version the plugin supports.
+func (p *ProjectFile) RemoveResource(gvk resource.GVK) error {
+	cfg, ok := p.config.(*cfgv3.Cfg)
+	if !ok {
+		return fmt.Errorf("remove resource: unsupported project version %s", p.config.GetVersion())
+	}
+	if !cfg.HasResource(gvk) {
+		return config.ResourceNotFoundError{GVK: gvk}
+	}
+
+	kept := make([]resource.Resource, 0, len(cfg.Resources)-1)
+	for _, res := range cfg.Resources {
+		if !gvk.IsEqualTo(res.GVK) {
+			kept = append(kept, res)
+		}
+	}
+	cfg.Resources = kept
+
+	return p.Save()
+}
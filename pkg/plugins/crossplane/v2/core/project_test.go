@@ -0,0 +1,59 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kubebuilder/v4/pkg/config"
+	cfgv3 "sigs.k8s.io/kubebuilder/v4/pkg/config/v3"
+	"sigs.k8s.io/kubebuilder/v4/pkg/model/resource"
+)
+
+func TestProjectFile_RemoveResource(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	cfg := cfgv3.New()
+	bucket := resource.GVK{Group: "storage", Version: "v1alpha1", Kind: "Bucket", Domain: "example.com"}
+	instance := resource.GVK{Group: "compute", Version: "v1alpha1", Kind: "Instance", Domain: "example.com"}
+	for _, gvk := range []resource.GVK{bucket, instance} {
+		_ = cfg.AddResource(resource.Resource{GVK: gvk})
+	}
+
+	p := NewProjectFile(cfg)
+	if err := p.RemoveResource(bucket); err != nil {
+		t.Fatalf("RemoveResource: %v", err)
+	}
+	if cfg.HasResource(bucket) || !cfg.HasResource(instance) {
+		t.Errorf("want only Bucket removed; Bucket=%v Instance=%v", cfg.HasResource(bucket), cfg.HasResource(instance))
+	}
+	saved, err := os.ReadFile("PROJECT")
+	if err != nil {
+		t.Fatalf("PROJECT not saved: %v", err)
+	}
+	if strings.Contains(string(saved), "Bucket") {
+		t.Errorf("saved PROJECT still lists Bucket:\n%s", saved)
+	}
+
+	var notFound config.ResourceNotFoundError
+	if err := p.RemoveResource(bucket); !errors.As(err, &notFound) {
+		t.Errorf("removing an absent resource: got %v, want ResourceNotFoundError", err)
+	}
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"sigs.k8s.io/kubebuilder/v4/pkg/config"
+	"sigs.k8s.io/kubebuilder/v4/pkg/model/resource"
+	"sigs.k8s.io/kubebuilder/v4/pkg/plugins/golang"
+)
+
+// PluginKey is the plugin's name, and the key of its section under `plugins:`
+// in PROJECT.
+const PluginKey = "crossplane." + golang.DefaultNameQualifier
+
+// ProjectSettings is the plugin's section of PROJECT: what a later run needs to
+// know about how the project was made. Templates read it back, so `update`
+// renders the same shape of provider that `init` did.
+type ProjectSettings struct {
+	// Version is the generator version that last touched the project.
+	Version string `json:"version,omitempty"`
+
+	// ClusterProviderConfig records `init --cluster-provider-config`: the
+	// provider offers a cluster-scoped ClusterProviderConfig alongside the
+	// namespaced ProviderConfig.
+	ClusterProviderConfig bool `json:"clusterProviderConfig,omitempty"`
+
+	// StorageVersions maps each kind served in more than one version, keyed
+	// "<group>/<Kind>", to its storage version: the conversion hub, and the
+	// version its controller reconciles. A kind with one version has no entry.
+	StorageVersions map[string]string `json:"storageVersions,omitempty"`
+
+	// Connections maps each kind that publishes a connection secret, keyed
+	// "<group>/<Kind>", to the secret keys `create api --connection-key`
+	// declared for it.
+	Connections map[string][]string `json:"connections,omitempty"`
+
+	// ExternalNames maps each kind created with `create api --external-name`,
+	// keyed "<group>/<Kind>", to its external-name strategy: name, generated,
+	// identifier or template:<go-template>.
+	ExternalNames map[string]string `json:"externalNames,omitempty"`
+
+	// FakeAPIs records the kinds created with `create api --fake-api`, keyed
+	// "<group>/<Kind>": their controllers talk to a generated fake of the
+	// external API.
+	FakeAPIs map[string]bool `json:"fakeAPIs,omitempty"`
+
+	// Migrations lists the upgrade migrations `update` has applied, by ID, so
+	// none runs twice.
+	Migrations []string `json:"migrations,omitempty"`
+
+	// Generator records the generator settings `init` ran with.
+	Generator GeneratorSettings `json:"generator,omitzero"`
+
+	// License records `init --license` and its copyright line. A project made
+	// before it was recorded has none, and keeps the Apache 2.0 header it was
+	// generated with (see License.WithDefaults).
+	License License `json:"license,omitzero"`
+}
+
+// Migrated reports whether the migration with the given ID has been applied.
+func (s ProjectSettings) Migrated(id string) bool {
+	return slices.Contains(s.Migrations, id)
+}
+
+// StorageVersion returns the storage version of res's kind, or "" when the kind
+// has a single version.
+func (s ProjectSettings) StorageVersion(res resource.Resource) string {
+	return s.StorageVersions[storageKey(res)]
+}
+
+// SetStorageVersion records version as the storage version of res's kind. An
+// empty version drops the entry, for a kind back down to a single version.
+func (s *ProjectSettings) SetStorageVersion(res resource.Resource, version string) {
+	if version == "" {
+		delete(s.StorageVersions, storageKey(res))
+		return
+	}
+	if s.StorageVersions == nil {
+		s.StorageVersions = map[string]string{}
+	}
+	s.StorageVersions[storageKey(res)] = version
+}
+
+// ConnectionKeys returns the connection secret keys res's kind publishes, or
+// nil when it declared none.
+func (s ProjectSettings) ConnectionKeys(res resource.Resource) []string {
+	return s.Connections[storageKey(res)]
+}
+
+// SetConnectionKeys records keys as the connection secret keys of res's kind.
+// No keys drops the entry.
+func (s *ProjectSettings) SetConnectionKeys(res resource.Resource, keys []string) {
+	if len(keys) == 0 {
+		delete(s.Connections, storageKey(res))
+		return
+	}
+	if s.Connections == nil {
+		s.Connections = map[string][]string{}
+	}
+	s.Connections[storageKey(res)] = keys
+}
+
+// ExternalName returns the external-name strategy of res's kind, or "" when
+// it chose none.
+func (s ProjectSettings) ExternalName(res resource.Resource) string {
+	return s.ExternalNames[storageKey(res)]
+}
+
+// SetExternalName records strategy as the external-name strategy of res's
+// kind. An empty strategy drops the entry.
+func (s *ProjectSettings) SetExternalName(res resource.Resource, strategy string) {
+	if strategy == "" {
+		delete(s.ExternalNames, storageKey(res))
+		return
+	}
+	if s.ExternalNames == nil {
+		s.ExternalNames = map[string]string{}
+	}
+	s.ExternalNames[storageKey(res)] = strategy
+}
+
+// FakeAPI reports whether res's kind talks to a generated fake API.
+func (s ProjectSettings) FakeAPI(res resource.Resource) bool {
+	return s.FakeAPIs[storageKey(res)]
+}
+
+// SetFakeAPI records whether res's kind talks to a generated fake API.
+func (s *ProjectSettings) SetFakeAPI(res resource.Resource, fake bool) {
+	if !fake {
+		delete(s.FakeAPIs, storageKey(res))
+		return
+	}
+	if s.FakeAPIs == nil {
+		s.FakeAPIs = map[string]bool{}
+	}
+	s.FakeAPIs[storageKey(res)] = true
+}
+
+func storageKey(res resource.Resource) string {
+	return res.Group + "/" + res.Kind
+}
+
+// LoadProjectSettings reads the plugin's section of PROJECT. A project that has
+// none — made before the section existed — gets the zero settings.
+func LoadProjectSettings(cfg config.Config) (ProjectSettings, error) {
+	var s ProjectSettings
+	err := cfg.DecodePluginConfig(PluginKey, &s)
+	if errors.As(err, &config.PluginKeyNotFoundError{}) {
+		return ProjectSettings{}, nil
+	}
+	if err != nil {
+		return ProjectSettings{}, fmt.Errorf("decode %s settings: %w", PluginKey, err)
+	}
+	return s, nil
+}
+
+// SaveProjectSettings writes the plugin's section into cfg. The caller saves
+// PROJECT.
+func SaveProjectSettings(cfg config.Config, s ProjectSettings) error {
+	if err := cfg.EncodePluginConfig(PluginKey, s); err != nil {
+		return fmt.Errorf("encode %s settings: %w", PluginKey, err)
+	}
+	return nil
+}
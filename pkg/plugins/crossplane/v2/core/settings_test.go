@@ -0,0 +1,136 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"reflect"
+	"testing"
+
+	cfgv3 "sigs.k8s.io/kubebuilder/v4/pkg/config/v3"
+	"sigs.k8s.io/kubebuilder/v4/pkg/model/resource"
+)
+
+func TestProjectSettings(t *testing.T) {
+	cfg := cfgv3.New()
+
+	got, err := LoadProjectSettings(cfg)
+	if err != nil {
+		t.Fatalf("load without a section: %v", err)
+	}
+	if !reflect.DeepEqual(got, ProjectSettings{}) {
+		t.Errorf("load without a section = %+v, want zero settings", got)
+	}
+
+	want := ProjectSettings{
+		Version:               "v1.2.3",
+		ClusterProviderConfig: true,
+		StorageVersions:       map[string]string{"storage/Bucket": "v1beta1"},
+		Connections:           map[string][]string{"storage/Bucket": {"endpoint", "password"}},
+		ExternalNames:         map[string]string{"storage/Bucket": "template:{{ .Namespace }}-{{ .Name }}"},
+		FakeAPIs:              map[string]bool{"storage/Bucket": true},
+		Migrations:            []string{"modular-layout"},
+		Generator: GeneratorSettings{
+			Defaults: DefaultValues{RepoPrefix: "github.com/acme", RegistryOrgs: []string{"xpkg.acme.io/acme"}},
+			Git:      GitConfig{BuildSubmoduleRef: "v0.1.0"},
+		},
+		License: License{ID: LicenseMIT, Owner: "Acme Corp", Year: 2030, Boilerplate: "// Copyright Acme Corp."},
+	}
+	if err := SaveProjectSettings(cfg, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err = LoadProjectSettings(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip = %+v, want %+v", got, want)
+	}
+}
+
+func TestStorageVersion(t *testing.T) {
+	bucket := resource.Resource{GVK: resource.GVK{Group: "storage", Version: "v1alpha1", Kind: "Bucket"}}
+	disk := resource.Resource{GVK: resource.GVK{Group: "storage", Version: "v1alpha1", Kind: "Disk"}}
+
+	var s ProjectSettings
+	if got := s.StorageVersion(bucket); got != "" {
+		t.Errorf("single-version kind: StorageVersion = %q, want empty", got)
+	}
+
+	s.SetStorageVersion(bucket, "v1beta1")
+	if got := s.StorageVersion(bucket); got != "v1beta1" {
+		t.Errorf("StorageVersion = %q, want v1beta1", got)
+	}
+	if got := s.StorageVersion(disk); got != "" {
+		t.Errorf("other kind: StorageVersion = %q, want empty", got)
+	}
+
+	s.SetStorageVersion(bucket, "")
+	if len(s.StorageVersions) != 0 {
+		t.Errorf("clearing left %v", s.StorageVersions)
+	}
+}
+
+// TestKindSettings covers the settings recorded for a kind rather than one of
+// its versions: set through one version, each reads back through another, and
+// clearing it leaves no entry.
+func TestKindSettings(t *testing.T) {
+	v1 := resource.Resource{GVK: resource.GVK{Group: "storage", Version: "v1alpha1", Kind: "Bucket"}}
+	v2 := resource.Resource{GVK: resource.GVK{Group: "storage", Version: "v1beta1", Kind: "Bucket"}}
+
+	cases := map[string]struct {
+		set    func(*ProjectSettings, resource.Resource)
+		clear  func(*ProjectSettings, resource.Resource)
+		get    func(ProjectSettings, resource.Resource) any
+		want   any
+		stored func(ProjectSettings) any
+	}{
+		"ConnectionKeys": {
+			set:    func(s *ProjectSettings, r resource.Resource) { s.SetConnectionKeys(r, []string{"endpoint"}) },
+			clear:  func(s *ProjectSettings, r resource.Resource) { s.SetConnectionKeys(r, nil) },
+			get:    func(s ProjectSettings, r resource.Resource) any { return s.ConnectionKeys(r) },
+			want:   []string{"endpoint"},
+			stored: func(s ProjectSettings) any { return s.Connections },
+		},
+		"ExternalName": {
+			set:    func(s *ProjectSettings, r resource.Resource) { s.SetExternalName(r, "identifier") },
+			clear:  func(s *ProjectSettings, r resource.Resource) { s.SetExternalName(r, "") },
+			get:    func(s ProjectSettings, r resource.Resource) any { return s.ExternalName(r) },
+			want:   "identifier",
+			stored: func(s ProjectSettings) any { return s.ExternalNames },
+		},
+		"FakeAPI": {
+			set:    func(s *ProjectSettings, r resource.Resource) { s.SetFakeAPI(r, true) },
+			clear:  func(s *ProjectSettings, r resource.Resource) { s.SetFakeAPI(r, false) },
+			get:    func(s ProjectSettings, r resource.Resource) any { return s.FakeAPI(r) },
+			want:   true,
+			stored: func(s ProjectSettings) any { return s.FakeAPIs },
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var s ProjectSettings
+			tc.set(&s, v1)
+			if got := tc.get(s, v2); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("another version of the kind: %s = %v, want %v", name, got, tc.want)
+			}
+			tc.clear(&s, v1)
+			if stored := reflect.ValueOf(tc.stored(s)); stored.Len() != 0 {
+				t.Errorf("clearing left %v", stored)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package v2
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
@@ -12,6 +13,7 @@ import (
 
 	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/automation"
 	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/core"
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/openapi"
 	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/templates/engine"
 	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/validation"
 )
@@ -19,7 +21,28 @@ import (
 var _ plugin.CreateAPISubcommand = &createAPISubcommand{}
 
 type createAPISubcommand struct {
-	Force bool
+	Force      bool
+	Namespaced bool
+
+	// fromOpenAPI and schemaRef are --from-openapi and --schema: the OpenAPI
+	// document the kind's types are generated from, and the schema in it.
+	fromOpenAPI string
+	schemaRef   string
+	// params and observe are the fields declared with --param and --observe.
+	params  []string
+	observe []string
+	// references are the fields declared with --reference.
+	references []string
+	schema     *openapi.Model
+	// connectionKeys are --connection-key: the keys of the kind's connection
+	// secret, recorded in PROJECT.
+	connectionKeys []string
+	// externalName is --external-name: how the kind's external resource is
+	// named, recorded in PROJECT.
+	externalName string
+	// fakeAPI is --fake-api: the kind talks to a generated fake of its
+	// external API, recorded in PROJECT.
+	fakeAPI bool
 
 	config       config.Config
 	resource     *resource.Resource
@@ -47,9 +70,37 @@ This command scaffolds a complete managed resource with:
   # Create a network resource
   %s create api --group=network --version=v1alpha1 --kind=VPC
 
+  # Create a cluster-scoped resource for a global external object
+  %s create api --group=org --version=v1alpha1 --kind=Settings --namespaced=false
+
   # Create resource and force overwrite existing files
-  %s create api --group=database --version=v1alpha1 --kind=PostgreSQL --force`,
-		cliMeta.CommandName, cliMeta.CommandName, cliMeta.CommandName, cliMeta.CommandName)
+  %s create api --group=database --version=v1alpha1 --kind=PostgreSQL --force
+
+  # Generate the Parameters and Observation structs from a vendor's OpenAPI 3 document
+  %s create api --group=storage --version=v1alpha1 --kind=Bucket \
+    --from-openapi=spec.yaml --schema='#/components/schemas/Bucket'
+
+  # Declare the fields up front
+  %s create api --group=storage --version=v1alpha1 --kind=Bucket \
+    --param=region:string,required,immutable --param=sizeGb:int --observe=arn:string
+
+  # Reference a Network of the network group by its ID, from a Ref or a Selector
+  %s create api --group=compute --version=v1alpha1 --kind=Subnet \
+    --param=cidrBlock:string,required --reference=networkId=Network.network
+
+  # Publish an endpoint and credentials in the connection secret
+  %s create api --group=database --version=v1alpha1 --kind=Instance \
+    --connection-key=endpoint,username,password
+
+  # Name each external resource after the namespace and name of its managed resource
+  %s create api --group=storage --version=v1alpha1 --kind=Bucket \
+    --external-name='template:{{ .Namespace }}-{{ .Name }}'
+
+  # Reconcile against a generated fake of the external API until the real client exists
+  %s create api --group=compute --version=v1alpha1 --kind=Instance --fake-api`,
+		cliMeta.CommandName, cliMeta.CommandName, cliMeta.CommandName, cliMeta.CommandName, cliMeta.CommandName,
+		cliMeta.CommandName, cliMeta.CommandName, cliMeta.CommandName, cliMeta.CommandName, cliMeta.CommandName,
+		cliMeta.CommandName)
 }
 
 func (p *createAPISubcommand) BindFlags(fs *pflag.FlagSet) {
@@ -57,10 +108,38 @@ func (p *createAPISubcommand) BindFlags(fs *pflag.FlagSet) {
 
 	defaults := p.pluginConfig.Defaults
 	fs.BoolVar(&p.Force, "force", defaults.Force, "overwrite existing files if they exist")
+	fs.BoolVar(&p.Namespaced, "namespaced", true,
+		"scope the managed resource to a namespace; set false for a cluster-scoped resource")
+	fs.StringVar(&p.fromOpenAPI, "from-openapi", "",
+		"generate the Parameters and Observation structs from a schema in this OpenAPI 3 document (YAML or JSON)")
+	fs.StringVar(&p.schemaRef, "schema", "",
+		"the schema to generate from, as #/components/schemas/<name> (default: the one named after --kind)")
+	fs.StringArrayVar(&p.params, "param", nil,
+		"a Parameters field, as name:type[,required][,immutable]; type is string, bool, int, int32, int64, "+
+			"number, or a []T or map[string]T of them (repeatable)")
+	fs.StringArrayVar(&p.observe, "observe", nil, "an Observation field, as name:type (repeatable)")
+	fs.StringArrayVar(&p.references, "reference", nil,
+		"a reference to another kind of the project, as name=Kind[.group]: an ID field and the Ref and "+
+			"Selector fields it is resolved from; the group defaults to this kind's own (repeatable)")
+	fs.StringSliceVar(&p.connectionKeys, "connection-key", nil,
+		"keys the kind publishes to its connection secret, comma-separated, such as endpoint,username,password")
+	fs.StringVar(&p.externalName, "external-name", "",
+		"how the kind's external resource is named: name (metadata.name), generated (metadata.name and a random "+
+			"suffix), identifier (assigned by the external API) or template:<go-template> (rendered from the kind)")
+	fs.BoolVar(&p.fakeAPI, "fake-api", false,
+		"generate a fake of the kind's external API in internal/fake/<kind>, with faults to inject, "+
+			"and have the controller talk to it in process")
 }
 
+// InjectConfig takes the project and the settings it was created with, which
+// the post-scaffolding commit runs with.
 func (p *createAPISubcommand) InjectConfig(c config.Config) error {
 	p.config = c
+	pluginConfig, err := loadPluginConfig(c)
+	if err != nil {
+		return validation.CreateAPIError("generator settings", err)
+	}
+	p.pluginConfig = pluginConfig
 	return nil
 }
 
@@ -72,7 +151,7 @@ func (p *createAPISubcommand) InjectResource(res *resource.Resource) error {
 		res.Domain = p.config.GetDomain()
 		res.API = &resource.API{
 			CRDVersion: "v1",
-			Namespaced: true,
+			Namespaced: p.Namespaced,
 		}
 		res.Controller = true
 	}
@@ -93,6 +172,109 @@ func (p *createAPISubcommand) PreScaffold(machinery.Filesystem) error {
 			fmt.Errorf("resource domain is required - ensure project is properly initialized"))
 	}
 
+	// A second version of an existing kind needs its types copied and a
+	// conversion between the two, which is create-version's job.
+	existing, err := p.config.GetResources()
+	if err != nil {
+		return validation.CreateAPIError("reading project resources", err)
+	}
+	for _, res := range existing {
+		if res.Group == p.resource.Group && res.Kind == p.resource.Kind && res.Version != p.resource.Version {
+			return validation.CreateAPIError("configuration check",
+				fmt.Errorf("kind %s already exists in %s/%s; add a version with "+
+					"`create-version --kind %s --from %s --to %s`",
+					res.Kind, res.Group, res.Version, res.Kind, res.Version, p.resource.Version))
+		}
+	}
+
+	if err := p.checkConnectionKeys(); err != nil {
+		return err
+	}
+	if p.externalName != "" {
+		if err := validation.NewValidator().ValidateExternalName(p.externalName); err != nil {
+			return validation.CreateAPIError("flag validation", err)
+		}
+	}
+	return p.loadSchema()
+}
+
+// checkConnectionKeys validates --connection-key. A connection secret is
+// written to the managed resource's namespace, so a cluster-scoped kind,
+// which has none, cannot publish one; and each key names a Connection field,
+// so no two may have the same Go name.
+func (p *createAPISubcommand) checkConnectionKeys() error {
+	if len(p.connectionKeys) == 0 {
+		return nil
+	}
+	if !p.Namespaced {
+		return validation.CreateAPIError("flag validation",
+			errors.New("--connection-key needs a namespaced kind; its secret is written to the kind's namespace"))
+	}
+	if err := validation.NewValidator().ValidateConnectionKeys(p.connectionKeys); err != nil {
+		return validation.CreateAPIError("flag validation", err)
+	}
+	names := map[string]string{}
+	for _, key := range p.connectionKeys {
+		name := openapi.GoName(key)
+		if other, ok := names[name]; ok {
+			return validation.CreateAPIError("flag validation",
+				fmt.Errorf("connection keys %s and %s would both be Connection.%s", other, key, name))
+		}
+		names[name] = key
+	}
+	return nil
+}
+
+// loadSchema builds the model of --schema in --from-openapi, or of the
+// declared fields, and adds the references to it, before anything is written,
+// so that a schema the generator cannot use fails the command.
+func (p *createAPISubcommand) loadSchema() error {
+	if err := p.buildSchema(); err != nil {
+		return err
+	}
+	if len(p.references) == 0 {
+		return nil
+	}
+	if p.schema == nil {
+		p.schema, _ = openapi.Declare(p.resource.Kind, nil, nil)
+	}
+	if _, err := addReferences(p.config, p.resource, p.schema, p.references); err != nil {
+		return validation.CreateAPIError("flag validation", err)
+	}
+	return nil
+}
+
+func (p *createAPISubcommand) buildSchema() error {
+	declared := len(p.params) > 0 || len(p.observe) > 0
+	if p.fromOpenAPI == "" {
+		if p.schemaRef != "" {
+			return validation.CreateAPIError("flag validation", errors.New("--schema needs --from-openapi"))
+		}
+		if !declared {
+			return nil
+		}
+		var err error
+		if p.schema, err = openapi.Declare(p.resource.Kind, p.params, p.observe); err != nil {
+			return validation.CreateAPIError("flag validation", err)
+		}
+		return nil
+	}
+	if declared {
+		return validation.CreateAPIError("flag validation",
+			errors.New("--param and --observe cannot be combined with --from-openapi; add fields to the "+
+				"generated kind with `add field`"))
+	}
+	doc, err := openapi.Load(p.fromOpenAPI)
+	if err != nil {
+		return validation.CreateAPIError("reading the OpenAPI document", err)
+	}
+	ref := p.schemaRef
+	if ref == "" {
+		ref = p.resource.Kind
+	}
+	if p.schema, err = openapi.Build(doc, ref, p.resource.Kind); err != nil {
+		return validation.CreateAPIError("generating types from the OpenAPI schema", err)
+	}
 	return nil
 }
 
@@ -102,9 +284,25 @@ func (p *createAPISubcommand) Scaffold(fs machinery.Filesystem) error {
 
 	p.ensureConfig()
 
-	scaffold := machinery.NewScaffold(fs,
+	// The templates read the kind's connection keys, external-name strategy
+	// and fake API from PROJECT, which PostScaffold saves.
+	if len(p.connectionKeys) > 0 || p.externalName != "" || p.fakeAPI {
+		settings, err := core.LoadProjectSettings(p.config)
+		if err != nil {
+			return validation.CreateAPIError("reading project settings", err)
+		}
+		settings.SetConnectionKeys(*p.resource, p.connectionKeys)
+		settings.SetExternalName(*p.resource, p.externalName)
+		settings.SetFakeAPI(*p.resource, p.fakeAPI)
+		if err := core.SaveProjectSettings(p.config, settings); err != nil {
+			return validation.CreateAPIError("recording project settings", err)
+		}
+	}
+
+	locked := trackWrites(fs.FS)
+	scaffold := machinery.NewScaffold(machinery.Filesystem{FS: locked},
 		machinery.WithConfig(p.config),
-		machinery.WithBoilerplate(engine.DefaultBoilerplate()),
+		machinery.WithBoilerplate(engine.ProjectBoilerplate(p.config)),
 		machinery.WithResource(p.resource),
 	)
 
@@ -115,6 +313,7 @@ func (p *createAPISubcommand) Scaffold(fs machinery.Filesystem) error {
 	apiTemplates, err := factory.GetAPITemplates(
 		engine.WithForce(p.Force),
 		engine.WithResource(p.resource),
+		engine.WithSchema(p.schema),
 	)
 	if err != nil {
 		return validation.CreateAPIError("template discovery", err)
@@ -137,6 +336,9 @@ func (p *createAPISubcommand) Scaffold(fs machinery.Filesystem) error {
 	if err := scaffold.Execute(allTemplates...); err != nil {
 		return validation.CreateAPIError("scaffolding", err)
 	}
+	if err := locked.saveLock(); err != nil {
+		return validation.CreateAPIError("scaffolding", err)
+	}
 
 	fmt.Printf("Successfully scaffolded Crossplane managed resource %s\n", p.resource.Kind)
 	return nil
@@ -151,7 +353,11 @@ func (p *createAPISubcommand) PostScaffold() error {
 	}
 
 	// Run API commit automation pipeline
-	pipeline := automation.NewAPICommitPipeline(p.pluginConfig, p.resource.Kind)
+	var scripts []string
+	if len(p.connectionKeys) > 0 {
+		scripts = append(scripts, fmt.Sprintf("test/e2e/%s-connection.sh", strings.ToLower(p.resource.Kind)))
+	}
+	pipeline := automation.NewAPICommitPipeline(p.pluginConfig, p.resource.Kind, scripts...)
 	fmt.Println("Running post-scaffolding automation...")
 	if err := pipeline.Run(); err != nil {
 		return validation.CreateAPIError("post-scaffolding automation", err)
@@ -159,7 +365,16 @@ func (p *createAPISubcommand) PostScaffold() error {
 
 	fmt.Printf("Crossplane managed resource %s created successfully!\n", p.resource.Kind)
 	fmt.Printf("Next steps:\n")
-	fmt.Printf("  1. Customize the %sParameters and %sObservation structs\n", p.resource.Kind, p.resource.Kind)
+	switch {
+	case p.fromOpenAPI != "":
+		fmt.Printf("  1. Review the %sParameters and %sObservation structs generated from %s\n",
+			p.resource.Kind, p.resource.Kind, p.fromOpenAPI)
+	case p.schema != nil:
+		fmt.Printf("  1. Document the %sParameters and %sObservation fields you declared\n",
+			p.resource.Kind, p.resource.Kind)
+	default:
+		fmt.Printf("  1. Customize the %sParameters and %sObservation structs\n", p.resource.Kind, p.resource.Kind)
+	}
 	fmt.Printf("  2. Implement the external client logic\n")
 	fmt.Printf("  3. Update controller reconciliation logic\n")
 	fmt.Printf("  4. Run 'make generate' to generate CRDs\n")
@@ -0,0 +1,467 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"go/format"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/pflag"
+	cfgv3 "sigs.k8s.io/kubebuilder/v4/pkg/config/v3"
+	"sigs.k8s.io/kubebuilder/v4/pkg/machinery"
+	"sigs.k8s.io/kubebuilder/v4/pkg/model/resource"
+
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/templates/engine"
+)
+
+func TestCreateAPINamespacedFlag(t *testing.T) {
+	cases := map[string]struct {
+		args []string
+		want bool
+	}{
+		"DefaultsToNamespaced": {args: nil, want: true},
+		"ClusterScoped":        {args: []string{"--namespaced=false"}, want: false},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := &createAPISubcommand{}
+			fs := pflag.NewFlagSet("create api", pflag.ContinueOnError)
+			p.BindFlags(fs)
+			if err := fs.Parse(tc.args); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg := cfgv3.New()
+			_ = cfg.SetRepository("github.com/example/provider-test")
+			_ = cfg.SetDomain("example.com")
+			if err := p.InjectConfig(cfg); err != nil {
+				t.Fatal(err)
+			}
+			res := &resource.Resource{GVK: resource.GVK{Group: "org", Version: "v1alpha1", Kind: "Settings"}}
+			if err := p.InjectResource(res); err != nil {
+				t.Fatal(err)
+			}
+			if res.API.Namespaced != tc.want {
+				t.Errorf("Namespaced = %v, want %v", res.API.Namespaced, tc.want)
+			}
+		})
+	}
+}
+
+func TestRenderClusterScopedAPI(t *testing.T) {
+	cfg := cfgv3.New()
+	_ = cfg.SetRepository("github.com/example/provider-test")
+	_ = cfg.SetDomain("example.com")
+	_ = cfg.SetProjectName("provider-test")
+	res := resource.Resource{
+		GVK:        resource.GVK{Group: "org", Version: "v1alpha1", Kind: "Settings", Domain: "example.com"},
+		Path:       "github.com/example/provider-test/apis/org/v1alpha1",
+		API:        &resource.API{CRDVersion: "v1", Namespaced: false},
+		Controller: true,
+	}
+	if err := cfg.AddResource(res); err != nil {
+		t.Fatal(err)
+	}
+
+	mem := afero.NewMemMapFs()
+	if err := renderAPIToMemFS(cfg, engine.NewFactory(cfg), res, machinery.Filesystem{FS: mem}); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+
+	types, err := afero.ReadFile(mem, "apis/org/v1alpha1/settings_types.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(types), "+kubebuilder:resource:scope=Cluster,") {
+		t.Errorf("types file is not cluster-scoped:\n%s", types)
+	}
+
+	// The kind's ProviderConfig is in the config namespace.
+	const config = "examples/provider/settings-config.yaml"
+	if b, err := afero.ReadFile(mem, config); err != nil || !strings.Contains(string(b), "namespace: crossplane-system") {
+		t.Errorf("%s has no ProviderConfig in crossplane-system (%v):\n%s", config, err, b)
+	}
+
+	var manifests int
+	_ = afero.Walk(mem, ".", func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || filepath.Ext(path) != ".yaml" || filepath.ToSlash(path) == config {
+			return err
+		}
+		manifests++
+		b, _ := afero.ReadFile(mem, path)
+		if strings.Contains(string(b), "namespace:") {
+			t.Errorf("%s sets a namespace on a cluster-scoped resource:\n%s", path, b)
+		}
+		return nil
+	})
+	if manifests == 0 {
+		t.Error("no manifests rendered")
+	}
+}
+
+// scaffoldKind renders storage/v1alpha1 Bucket with the create api flags in args.
+func scaffoldKind(t *testing.T, args ...string) (afero.Fs, error) {
+	t.Helper()
+	p := &createAPISubcommand{}
+	fs := pflag.NewFlagSet("create api", pflag.ContinueOnError)
+	p.BindFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	cfg := cfgv3.New()
+	_ = cfg.SetRepository("github.com/example/provider-test")
+	_ = cfg.SetDomain("example.com")
+	_ = cfg.SetProjectName("provider-test")
+	_ = p.InjectConfig(cfg)
+	_ = p.InjectResource(&resource.Resource{GVK: resource.GVK{Group: "storage", Version: "v1alpha1", Kind: "Bucket"}})
+	mem := afero.NewMemMapFs()
+	if err := p.PreScaffold(machinery.Filesystem{FS: mem}); err != nil {
+		return nil, err
+	}
+	return mem, p.Scaffold(machinery.Filesystem{FS: mem})
+}
+
+func TestCreateAPIFromOpenAPI(t *testing.T) {
+	spec := filepath.Join(t.TempDir(), "spec.yaml")
+	_ = os.WriteFile(spec, []byte(`openapi: 3.1.0
+components:
+  schemas:
+    StorageBucket:
+      type: object
+      required: [name]
+      properties:
+        name: {type: string, description: The bucket name.}
+        arn: {type: string, readOnly: true}
+`), 0o644)
+
+	mem, err := scaffoldKind(t, "--from-openapi="+spec, "--schema=#/components/schemas/StorageBucket")
+	if err != nil {
+		t.Fatal(err)
+	}
+	read := func(path string) string {
+		b, err := afero.ReadFile(mem, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+	types := read("apis/storage/v1alpha1/bucket_types.go")
+	for _, want := range []string{
+		"\t// The bucket name.\n\t// +kubebuilder:validation:Required\n\tName string `json:\"name\"`\n",
+		"type BucketObservation struct {\n\t// +optional\n\tARN *string `json:\"arn,omitempty\"`\n}",
+	} {
+		if !strings.Contains(types, want) {
+			t.Errorf("types file does not contain %q:\n%s", want, types)
+		}
+	}
+	for _, path := range []string{
+		"apis/storage/v1alpha1/bucket_types.go",
+		"internal/controller/bucket/external.go",
+		"examples/storage/bucket.yaml",
+		"test/e2e/bucket-lifecycle.yaml",
+		"test/behavior/bucket-pause/chainsaw-test.yaml",
+	} {
+		if strings.Contains(strings.ToLower(read(path)), "configurablefield") {
+			t.Errorf("%s still uses the placeholder field", path)
+		}
+	}
+	if example := read("examples/storage/bucket.yaml"); !strings.Contains(example, "  forProvider:\n    name: example\n") {
+		t.Errorf("example manifest has no sample:\n%s", example)
+	}
+
+	if _, err := scaffoldKind(t, "--schema=Bucket"); err == nil {
+		t.Error("--schema without --from-openapi was accepted")
+	}
+	if _, err := scaffoldKind(t, "--from-openapi="+spec); err == nil || !strings.Contains(err.Error(), "no schema") {
+		t.Errorf("without --schema, error = %v; want the schema named after the kind looked up", err)
+	}
+}
+
+func TestCreateAPIDeclaredFields(t *testing.T) {
+	mem, err := scaffoldKind(t, "--param=region:string,required,immutable", "--param=sizeGb:int", "--observe=arn:string")
+	if err != nil {
+		t.Fatal(err)
+	}
+	types, _ := afero.ReadFile(mem, "apis/storage/v1alpha1/bucket_types.go")
+	for _, want := range []string{
+		"\t// +kubebuilder:validation:Required\n" +
+			"\t// +kubebuilder:validation:XValidation:rule=\"self == oldSelf\",message=\"Value is immutable\"\n" +
+			"\tRegion string `json:\"region\"`\n",
+		"\t// +optional\n\tSizeGb *int64 `json:\"sizeGb,omitempty\"`\n",
+		"type BucketObservation struct {\n\t// +optional\n\tARN *string `json:\"arn,omitempty\"`\n}",
+	} {
+		if !strings.Contains(string(types), want) {
+			t.Errorf("types file does not contain %q:\n%s", want, types)
+		}
+	}
+	if example, _ := afero.ReadFile(mem, "examples/storage/bucket.yaml"); !strings.Contains(string(example),
+		"  forProvider:\n    region: example\n    sizeGb: 1\n") {
+		t.Errorf("example manifest has no sample:\n%s", example)
+	}
+
+	if compare, _ := afero.ReadFile(mem, "internal/controller/bucket/zz_compare.go"); !strings.Contains(string(compare),
+		"func IsUpToDate(spec v1alpha1.BucketParameters, observed v1alpha1.BucketObservation) (upToDate bool, diff string)") {
+		t.Errorf("zz_compare.go has no typed IsUpToDate:\n%s", compare)
+	}
+
+	if _, err := scaffoldKind(t, "--param=region:text"); err == nil || !strings.Contains(err.Error(), "unknown type") {
+		t.Errorf("bad --param error = %v", err)
+	}
+	if _, err := scaffoldKind(t, "--from-openapi=spec.yaml", "--param=region:string"); err == nil ||
+		!strings.Contains(err.Error(), "cannot be combined") {
+		t.Errorf("--param with --from-openapi error = %v", err)
+	}
+}
+
+func TestCreateAPIConnectionKeys(t *testing.T) {
+	mem, err := scaffoldKind(t, "--connection-key=endpoint,username,ca.crt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	read := func(path string) string {
+		b, err := afero.ReadFile(mem, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+	for path, wants := range map[string][]string{
+		"internal/controller/bucket/zz_connection.go": {
+			"\tConnectionKeyCaCrt    = \"ca.crt\"\n",
+			"type Connection struct {\n\tEndpoint string\n\tUsername string\n\tCaCrt    string\n}",
+			"func ObservedConnection(observed v1alpha1.BucketObservation) Connection {",
+		},
+		"internal/controller/bucket/external.go": {
+			"ConnectionDetails: ObservedConnection(cr.Status.AtProvider).ConnectionDetails(),",
+			"\t\t\tEndpoint: \"example-endpoint\",\n",
+		},
+		"examples/storage/bucket.yaml": {"  writeConnectionSecretToRef:\n    name: example-bucket-connection\n"},
+		"test/e2e/bucket-lifecycle.yaml": {
+			"    uptest.upbound.io/post-assert-hook: bucket-connection.sh\n",
+			"  writeConnectionSecretToRef:\n    name: e2e-bucket-lifecycle-connection\n",
+		},
+		"test/e2e/bucket-connection.sh": {"KEYS=(endpoint username ca.crt)\n", "SECRET=e2e-bucket-lifecycle-connection\n"},
+	} {
+		got := read(path)
+		for _, want := range wants {
+			if !strings.Contains(got, want) {
+				t.Errorf("%s does not contain %q:\n%s", path, want, got)
+			}
+		}
+	}
+
+	// Without keys, the kind publishes nothing and has nothing to check.
+	plain, err := scaffoldKind(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"internal/controller/bucket/zz_connection.go", "test/e2e/bucket-connection.sh"} {
+		if exists, _ := afero.Exists(plain, path); exists {
+			t.Errorf("%s rendered for a kind without connection keys", path)
+		}
+	}
+
+	for args, wantErr := range map[string]string{
+		"--connection-key=end point":          "must be a Secret key",
+		"--connection-key=user-name,userName": "would both be Connection.UserName",
+	} {
+		if _, err := scaffoldKind(t, args); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("%s: error = %v, want %q", args, err, wantErr)
+		}
+	}
+	if _, err := scaffoldKind(t, "--connection-key=endpoint", "--namespaced=false"); err == nil ||
+		!strings.Contains(err.Error(), "needs a namespaced kind") {
+		t.Errorf("cluster-scoped kind: error = %v", err)
+	}
+}
+
+func TestCreateAPIExternalName(t *testing.T) {
+	for strategy, wants := range map[string][]string{
+		"name": {
+			"managed.WithInitializers(managed.NewNameAsExternalName(mgr.GetClient())),",
+			"managed.WithDeterministicExternalName(true),",
+		},
+		"generated": {
+			`meta.SetExternalName(mg, mg.GetName()+"-"+rand.String(8))`,
+			"managed.WithDeterministicExternalName(true),",
+		},
+		"identifier": {
+			"return []managed.ReconcilerOption{managed.WithInitializers()}, nil",
+			"meta.SetExternalName(cr, string(cr.GetUID()))",
+		},
+		"template:{{ .Namespace }}/{{ .Name }}": {
+			`Parse("{{ .Namespace }}/{{ .Name }}"))`,
+			"if err := externalName.Execute(&name, cr); err != nil {",
+		},
+	} {
+		mem, err := scaffoldKind(t, "--external-name="+strategy)
+		if err != nil {
+			t.Fatalf("%s: %v", strategy, err)
+		}
+		src, err := afero.ReadFile(mem, "internal/controller/bucket/external.go")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if formatted, err := format.Source(src); err != nil || string(formatted) != string(src) {
+			t.Errorf("%s: external.go is not gofmt-clean (%v):\n%s", strategy, err, src)
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(src), want) {
+				t.Errorf("%s: external.go does not contain %q:\n%s", strategy, want, src)
+			}
+		}
+		if strings.Contains(string(src), `"my-external-name"`) {
+			t.Errorf("%s: Create still sets the placeholder external name", strategy)
+		}
+		if exists, _ := afero.Exists(mem, "test/behavior/bucket-import/chainsaw-test.yaml"); !exists {
+			t.Errorf("%s: no import test", strategy)
+		}
+	}
+
+	// Without a strategy, the kind keeps the placeholder and has no import test.
+	plain, err := scaffoldKind(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exists, _ := afero.Exists(plain, "test/behavior/bucket-import/chainsaw-test.yaml"); exists {
+		t.Error("import test rendered for a kind without an external-name strategy")
+	}
+
+	for _, strategy := range []string{"uuid", "template:", "template:{{ .Name"} {
+		if _, err := scaffoldKind(t, "--external-name="+strategy); err == nil {
+			t.Errorf("%s: no error", strategy)
+		}
+	}
+}
+
+func TestCreateAPIFakeAPI(t *testing.T) {
+	for _, args := range [][]string{{"--fake-api"}, {"--fake-api", "--external-name=identifier"}} {
+		mem, err := scaffoldKind(t, args...)
+		if err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+		for _, path := range []string{
+			"internal/fake/bucket/client.go",
+			"internal/fake/bucket/server.go",
+			"internal/controller/bucket/external.go",
+		} {
+			src, err := afero.ReadFile(mem, path)
+			if err != nil {
+				t.Fatalf("%v: %v", args, err)
+			}
+			if formatted, err := format.Source(src); err != nil || string(formatted) != string(src) {
+				t.Errorf("%v: %s is not gofmt-clean (%v):\n%s", args, path, err, src)
+			}
+		}
+		src, _ := afero.ReadFile(mem, "internal/controller/bucket/external.go")
+		for _, want := range []string{
+			`fakeapi "github.com/example/provider-test/internal/fake/bucket"`,
+			"got, err := e.api.Get(fakeapi.WithFaults(ctx, cr), meta.GetExternalName(cr))",
+			"cr.Status.AtProvider = got.Observation",
+		} {
+			if !strings.Contains(string(src), want) {
+				t.Errorf("%v: external.go does not contain %q:\n%s", args, want, src)
+			}
+		}
+		if exists, _ := afero.Exists(mem, "test/behavior/bucket-api-errors/chainsaw-test.yaml"); !exists {
+			t.Errorf("%v: no api-errors test", args)
+		}
+	}
+
+	// Without the flag, the kind has no fake to talk to.
+	plain, err := scaffoldKind(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"internal/fake/bucket/server.go", "test/behavior/bucket-api-errors/chainsaw-test.yaml"} {
+		if exists, _ := afero.Exists(plain, path); exists {
+			t.Errorf("%s rendered for a kind without a fake API", path)
+		}
+	}
+	if src, _ := afero.ReadFile(plain, "internal/controller/bucket/external.go"); strings.Contains(string(src), "fakeapi") {
+		t.Error("external.go talks to a fake API the kind does not have")
+	}
+}
+
+func TestCreateAPIExternalTest(t *testing.T) {
+	for _, args := range [][]string{
+		nil,
+		{"--external-name=identifier"},
+		{"--external-name=template:{{ .Name }}"},
+		{"--connection-key=endpoint,password"},
+		{"--param=region:string,required", "--connection-key=endpoint"},
+		{"--fake-api"},
+		{"--fake-api", "--external-name=identifier", "--connection-key=endpoint"},
+	} {
+		mem, err := scaffoldKind(t, args...)
+		if err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+		src, err := afero.ReadFile(mem, "internal/controller/bucket/external_test.go")
+		if err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+		if formatted, err := format.Source(src); err != nil || string(formatted) != string(src) {
+			t.Errorf("%v: external_test.go is not gofmt-clean (%v):\n%s", args, err, src)
+		}
+		for _, want := range []string{"func TestObserve(", "func TestCreate(", "func TestUpdate(", "func TestDelete("} {
+			if !strings.Contains(string(src), want) {
+				t.Errorf("%v: external_test.go has no %s", args, want)
+			}
+		}
+		// A kind with a fake API is tested against one of its own.
+		if got, want := strings.Contains(string(src), "fakeapi.NewServer()"), slices.Contains(args, "--fake-api"); got != want {
+			t.Errorf("%v: external_test.go serves a fake API = %v, want %v", args, got, want)
+		}
+	}
+}
+
+func TestCreateAPIReference(t *testing.T) {
+	// The project has no other kind, so Bucket references one of its own.
+	mem, err := scaffoldKind(t, "--reference=parentId=Bucket")
+	if err != nil {
+		t.Fatal(err)
+	}
+	types, _ := afero.ReadFile(mem, "apis/storage/v1alpha1/bucket_types.go")
+	for _, want := range []string{
+		"\t// +crossplane:generate:reference:type=Bucket\n\t// +optional\n\tParentID *string `json:\"parentId,omitempty\"`\n",
+		"\tParentIDRef *xpv2.NamespacedReference `json:\"parentIdRef,omitempty\"`\n",
+		"\tParentIDSelector *xpv2.NamespacedSelector `json:\"parentIdSelector,omitempty\"`\n",
+	} {
+		if !strings.Contains(string(types), want) {
+			t.Errorf("types file does not contain %q:\n%s", want, types)
+		}
+	}
+	if example, _ := afero.ReadFile(mem, "examples/storage/bucket.yaml"); !strings.Contains(string(example),
+		"  forProvider:\n    parentIdRef:\n      name: example\n") {
+		t.Errorf("example manifest has no reference:\n%s", example)
+	}
+
+	if _, err := scaffoldKind(t, "--reference=networkId=Network"); err == nil ||
+		!strings.Contains(err.Error(), "create it first") {
+		t.Errorf("reference to a missing kind: error = %v", err)
+	}
+	if _, err := scaffoldKind(t, "--param=parentId:string", "--reference=parentId=Bucket"); err == nil ||
+		!strings.Contains(err.Error(), "already have a field parentId") {
+		t.Errorf("reference named like a --param: error = %v", err)
+	}
+}
@@ -0,0 +1,431 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kubebuilder/v4/pkg/config"
+	"sigs.k8s.io/kubebuilder/v4/pkg/machinery"
+	"sigs.k8s.io/kubebuilder/v4/pkg/model/resource"
+
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/automation"
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/core"
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/templates/engine"
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/validation"
+)
+
+// storageVersionMarker makes controller-gen mark a version as the CRD's storage
+// version. Exactly one version of a multi-version kind carries it.
+const storageVersionMarker = "// +kubebuilder:storageversion"
+
+// webhookServerFiles are the files outside a kind's own that change with the
+// webhooks the provider serves: main.go starts the webhook server, and
+// generate.go writes the admission webhook manifests.
+var webhookServerFiles = []string{"cmd/provider/main.go", "apis/generate.go"}
+
+// createVersionOptions are the flags of `create-version`.
+type createVersionOptions struct {
+	group, kind, from, to string
+	storage               bool
+}
+
+// NewCreateVersionCommand returns the `create-version` command, registered on the
+// CLI via cli.WithExtraCommands (kubebuilder's `create` has no hook for it).
+func NewCreateVersionCommand() *cobra.Command {
+	var opts createVersionOptions
+	cmd := &cobra.Command{
+		Use:   "create-version",
+		Short: "Add an API version to an existing managed resource kind",
+		Long: `Add an API version to a managed resource kind created with 'create api'.
+
+The --from version's *_types.go is copied into the new version's package, so the
+two start out identical. One version of the kind is its storage version: it is
+marked +kubebuilder:storageversion, it is the conversion hub every other version
+converts to and from, and it is the version the kind's one controller reconciles.
+The storage version stays where it is unless --storage moves it to the new version.
+
+Each other version gets a <kind>_conversion.go converting it to and from the hub.
+It is yours: it copies fields across while the versions share a shape and stops
+compiling where they diverge, which is where your conversion code goes.
+
+The working tree must be clean, so the new version lands as one reviewable commit.`,
+		Example: `  # Serve Bucket in v1beta1 as well, still storing v1alpha1
+  xp-provider-gen create-version --kind Bucket --from v1alpha1 --to v1beta1
+
+  # Serve and store v1beta1; the controller moves to it
+  xp-provider-gen create-version --kind Bucket --from v1alpha1 --to v1beta1 --storage`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return runCreateVersion(context.Background(), opts, os.Stdout)
+		},
+	}
+	cmd.Flags().StringVar(&opts.kind, "kind", "", "resource kind")
+	cmd.Flags().StringVar(&opts.from, "from", "", "existing version whose types are copied")
+	cmd.Flags().StringVar(&opts.to, "to", "", "new version to add")
+	cmd.Flags().StringVar(&opts.group, "group", "",
+		"resource group; required only when several groups have the kind at --from")
+	cmd.Flags().BoolVar(&opts.storage, "storage", false,
+		"make the new version the storage version, reconciled by the controller")
+	for _, name := range []string{"kind", "from", "to"} {
+		_ = cmd.MarkFlagRequired(name)
+	}
+	return cmd
+}
+
+func runCreateVersion(ctx context.Context, opts createVersionOptions, out io.Writer) error {
+	if err := requireCleanTree(ctx); err != nil {
+		return err
+	}
+	st, err := loadProjectStore()
+	if err != nil {
+		return err
+	}
+	cfg := st.Config()
+	if err := validateProject(cfg); err != nil {
+		return err
+	}
+	pluginConfig, err := loadPluginConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	from, versions, err := findKindVersions(cfg, opts)
+	if err != nil {
+		return err
+	}
+	added := from
+	added.Version = opts.to
+	added.Path = fmt.Sprintf("%s/apis/%s/%s", cfg.GetRepository(), added.Group, added.Version)
+	if err := validation.NewValidator().ValidateResource(&added); err != nil {
+		return validation.CreateAPIError("resource validation", err)
+	}
+
+	settings, err := core.LoadProjectSettings(cfg)
+	if err != nil {
+		return err
+	}
+	hub := settings.StorageVersion(from)
+	if hub == "" {
+		hub = versions[0] // a single-version kind stores its only version
+	}
+	newHub := hub
+	if opts.storage {
+		newHub = added.Version
+	}
+
+	disk := afero.NewOsFs()
+	if err := addVersionFiles(cfg, disk, from, added, append(versions, added.Version), hub, newHub); err != nil {
+		return err
+	}
+
+	settings.SetStorageVersion(added, newHub)
+	if err := core.SaveProjectSettings(cfg, settings); err != nil {
+		return err
+	}
+	if err := core.NewProjectFile(cfg).AddResource(added); err != nil {
+		return fmt.Errorf("adding %s %s to PROJECT: %w", added.Kind, added.Version, err)
+	}
+	result, err := refreshKind(cfg, disk, added.Group, added.Kind)
+	if err != nil {
+		return fmt.Errorf("rendering %s %s: %w", added.Kind, added.Version, err)
+	}
+	result.print()
+
+	pipeline := automation.NewAPIVersionPipeline(pluginConfig, added.Kind, added.Version)
+	fmt.Fprintln(out, "Running post-scaffolding automation...")
+	if err := pipeline.Run(); err != nil {
+		return fmt.Errorf("post-scaffolding automation: %w", err)
+	}
+	fmt.Fprintf(out, "%s %s added; %s is the storage version.\n", added.Kind, added.Version, newHub)
+	return nil
+}
+
+// findKindVersions finds the resource named by opts at its --from version and
+// returns it with every version the kind is served in, sorted. It rejects a
+// --to version the kind already has.
+func findKindVersions(cfg config.Config, opts createVersionOptions) (resource.Resource, []string, error) {
+	all, err := cfg.GetResources()
+	if err != nil {
+		return resource.Resource{}, nil, fmt.Errorf("reading project resources: %w", err)
+	}
+	var matches []resource.Resource
+	for _, r := range all {
+		if r.Kind == opts.kind && r.Version == opts.from && (opts.group == "" || r.Group == opts.group) {
+			matches = append(matches, r)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return resource.Resource{}, nil, fmt.Errorf("kind %s has no version %s in this project", opts.kind, opts.from)
+	case 1:
+	default:
+		return resource.Resource{}, nil, fmt.Errorf("kind %s %s is in several groups; pick one with --group",
+			opts.kind, opts.from)
+	}
+	from := matches[0]
+
+	var versions []string
+	for _, r := range all {
+		if r.Group != from.Group || r.Kind != from.Kind {
+			continue
+		}
+		if r.Version == opts.to {
+			return resource.Resource{}, nil, fmt.Errorf("%s/%s %s already exists", r.Group, r.Version, r.Kind)
+		}
+		versions = append(versions, r.Version)
+	}
+	sort.Strings(versions)
+	return from, versions, nil
+}
+
+// addVersionFiles writes the files create-version derives from the user's own
+// code rather than from templates: the new version's types, copied from --from;
+// the storage version marker on every version's types; and, when the hub moves,
+// the user-owned files that imported it, pointed at the new hub instead.
+func addVersionFiles(cfg config.Config, disk afero.Fs, from, added resource.Resource,
+	versions []string, hub, newHub string,
+) error {
+	src, err := afero.ReadFile(disk, apiFile(from, from.Version, "_types.go"))
+	if err != nil {
+		return fmt.Errorf("reading %s %s types: %w", from.Kind, from.Version, err)
+	}
+	types, err := copyTypes(src, from.Version, added.Version)
+	if err != nil {
+		return err
+	}
+	typesPath := apiFile(added, added.Version, "_types.go")
+	if exists, _ := afero.Exists(disk, typesPath); exists {
+		return fmt.Errorf("%s already exists", typesPath)
+	}
+	if err := disk.MkdirAll(path.Dir(typesPath), 0o755); err != nil {
+		return err
+	}
+	if err := afero.WriteFile(disk, typesPath, types, 0o644); err != nil {
+		return err
+	}
+
+	for _, v := range versions {
+		if err := rewriteFile(disk, apiFile(from, v, "_types.go"), func(b []byte) ([]byte, error) {
+			return setStorageMarker(b, from.Kind, v == newHub)
+		}); err != nil {
+			return err
+		}
+	}
+	if newHub == hub {
+		return nil
+	}
+
+	// The old hub becomes a spoke: it loses its Hub() and gains a conversion file
+	// when the kind is re-rendered.
+	if err := disk.Remove(apiFile(from, hub, "_hub.go")); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	oldImport := fmt.Sprintf("%s/apis/%s/%s", cfg.GetRepository(), from.Group, hub)
+	newImport := fmt.Sprintf("%s/apis/%s/%s", cfg.GetRepository(), from.Group, newHub)
+	retarget := func(b []byte) ([]byte, error) {
+		if core.IsToolOwned(b) {
+			return b, nil // re-rendered against the new hub
+		}
+		return retargetImport(b, oldImport, newImport)
+	}
+	controllerFiles, err := afero.Glob(disk, path.Join("internal/controller", strings.ToLower(from.Kind), "*.go"))
+	if err != nil {
+		return err
+	}
+	for _, p := range controllerFiles {
+		if err := rewriteFile(disk, p, retarget); err != nil {
+			return err
+		}
+	}
+	for _, v := range versions {
+		if err := rewriteFile(disk, apiFile(from, v, "_conversion.go"), retarget); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apiFile returns the path of one of a kind's files in the API package of the
+// given version, such as "apis/storage/v1beta1/bucket_types.go" for "_types.go".
+func apiFile(res resource.Resource, version, suffix string) string {
+	return path.Join("apis", strings.ToLower(res.Group), version, strings.ToLower(res.Kind)+suffix)
+}
+
+// rewriteFile applies edit to the file at p, if there is one.
+func rewriteFile(disk afero.Fs, p string, edit func([]byte) ([]byte, error)) error {
+	b, err := afero.ReadFile(disk, p)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	edited, err := edit(b)
+	if err != nil {
+		return fmt.Errorf("%s: %w", p, err)
+	}
+	if bytes.Equal(edited, b) {
+		return nil
+	}
+	return afero.WriteFile(disk, p, edited, 0o644)
+}
+
+// copyTypes returns a types file moved from one version's package to another's.
+func copyTypes(src []byte, from, to string) ([]byte, error) {
+	clause := regexp.MustCompile(`(?m)^package ` + regexp.QuoteMeta(from) + `$`)
+	loc := clause.FindIndex(src)
+	if loc == nil {
+		return nil, fmt.Errorf("types file is not in package %s", from)
+	}
+	out := append([]byte{}, src[:loc[0]]...)
+	out = append(out, "package "+to...)
+	return append(out, src[loc[1]:]...), nil
+}
+
+// setStorageMarker adds or removes the storageversion marker in the doc comment
+// of kind's type declaration, placing a new marker last, next to the type.
+func setStorageMarker(src []byte, kind string, storage bool) ([]byte, error) {
+	lines := strings.Split(string(src), "\n")
+	decl := -1
+	for i, l := range lines {
+		if strings.HasPrefix(l, "type "+kind+" struct") {
+			decl = i
+			break
+		}
+	}
+	if decl < 0 {
+		return nil, fmt.Errorf("no declaration of type %s", kind)
+	}
+	marker := -1
+	for i := decl - 1; i >= 0 && strings.HasPrefix(lines[i], "//"); i-- {
+		if strings.TrimSpace(lines[i]) == storageVersionMarker {
+			marker = i
+		}
+	}
+	switch {
+	case storage && marker < 0:
+		lines = append(lines[:decl], append([]string{storageVersionMarker}, lines[decl:]...)...)
+	case !storage && marker >= 0:
+		lines = append(lines[:marker], lines[marker+1:]...)
+	}
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+// retargetImport points a Go file's import of one package at another, renaming
+// the package's identifiers along with it unless the import is named. A file
+// that does not import from is returned unchanged.
+func retargetImport(src []byte, from, to string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	var spec *ast.ImportSpec
+	for _, s := range file.Imports {
+		if p, _ := strconv.Unquote(s.Path.Value); p == from {
+			spec = s
+		}
+	}
+	if spec == nil {
+		return src, nil
+	}
+	spec.Path.Value = strconv.Quote(to)
+	if spec.Name == nil {
+		oldName, newName := path.Base(from), path.Base(to)
+		ast.Inspect(file, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok {
+				if id, ok := sel.X.(*ast.Ident); ok && id.Name == oldName {
+					id.Name = newName
+				}
+			}
+			return true
+		})
+	}
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// refreshKind re-renders every version of one kind, along with webhookServerFiles,
+// and applies them through the ownership gate, then regenerates the registration
+// files. Which of the kind's versions renders the controller and the conversion
+// files follows the storage version in PROJECT, and which webhooks it registers
+// follows its resource, so this runs whenever either changes.
+func refreshKind(cfg config.Config, disk afero.Fs, group, kind string) (reconcileResult, error) {
+	full := afero.NewMemMapFs()
+	if err := renderToMemFS(cfg, machinery.Filesystem{FS: full}); err != nil {
+		return reconcileResult{}, err
+	}
+	resources, err := cfg.GetResources()
+	if err != nil {
+		return reconcileResult{}, fmt.Errorf("reading project resources: %w", err)
+	}
+
+	paths := append([]string{}, webhookServerFiles...)
+	factory := engine.NewFactory(cfg)
+	for _, res := range resources {
+		if res.Group != group || res.Kind != kind {
+			continue
+		}
+		rendered, err := renderedPaths(cfg, factory, res)
+		if err != nil {
+			return reconcileResult{}, err
+		}
+		paths = append(paths, rendered...)
+	}
+	kindFiles := afero.NewMemMapFs()
+	for _, p := range paths {
+		b, err := afero.ReadFile(full, p)
+		if err != nil {
+			return reconcileResult{}, err
+		}
+		if err := afero.WriteFile(kindFiles, p, b, 0o644); err != nil {
+			return reconcileResult{}, err
+		}
+	}
+	result, err := reconcile(kindFiles, disk)
+	if err != nil {
+		return reconcileResult{}, err
+	}
+
+	locked := trackWrites(disk)
+	scaffold := machinery.NewScaffold(machinery.Filesystem{FS: locked},
+		machinery.WithConfig(cfg),
+		machinery.WithBoilerplate(engine.ProjectBoilerplate(cfg)),
+	)
+	if err := scaffold.Execute(engine.CoreGenerators(cfg, resources)...); err != nil {
+		return reconcileResult{}, fmt.Errorf("regenerating registration files: %w", err)
+	}
+	return result, locked.saveLock()
+}
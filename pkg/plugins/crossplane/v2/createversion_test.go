@@ -0,0 +1,183 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"sigs.k8s.io/kubebuilder/v4/pkg/config"
+	cfgv3 "sigs.k8s.io/kubebuilder/v4/pkg/config/v3"
+	"sigs.k8s.io/kubebuilder/v4/pkg/machinery"
+	"sigs.k8s.io/kubebuilder/v4/pkg/model/resource"
+
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/core"
+)
+
+// multiVersionProject is a provider serving Bucket in v1alpha1 and v1beta1 with
+// v1beta1 stored.
+func multiVersionProject(t *testing.T) config.Config {
+	t.Helper()
+	cfg := cfgv3.New()
+	_ = cfg.SetRepository("github.com/example/provider-test")
+	_ = cfg.SetDomain("example.com")
+	_ = cfg.SetProjectName("provider-test")
+	var settings core.ProjectSettings
+	for _, version := range []string{"v1alpha1", "v1beta1"} {
+		res := resource.Resource{
+			GVK:        resource.GVK{Group: "storage", Version: version, Kind: kindBucket, Domain: "example.com"},
+			Plural:     "buckets",
+			Path:       "github.com/example/provider-test/apis/storage/" + version,
+			API:        &resource.API{CRDVersion: "v1", Namespaced: true},
+			Controller: true,
+		}
+		if err := cfg.AddResource(res); err != nil {
+			t.Fatal(err)
+		}
+		settings.SetStorageVersion(res, "v1beta1")
+	}
+	if err := core.SaveProjectSettings(cfg, settings); err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestRenderMultiVersionKind(t *testing.T) {
+	mem := afero.NewMemMapFs()
+	if err := renderToMemFS(multiVersionProject(t), machinery.Filesystem{FS: mem}); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+
+	for path, want := range map[string]bool{
+		"apis/storage/v1beta1/bucket_hub.go":         true,
+		"apis/storage/v1alpha1/bucket_conversion.go": true,
+		"apis/storage/v1alpha1/bucket_hub.go":        false,
+		"apis/storage/v1beta1/bucket_conversion.go":  false,
+	} {
+		if exists, _ := afero.Exists(mem, path); exists != want {
+			t.Errorf("%s rendered = %v, want %v", path, exists, want)
+		}
+	}
+
+	for path, wants := range map[string][]string{
+		"apis/storage/v1beta1/bucket_types.go": {"// +kubebuilder:storageversion\ntype Bucket struct"},
+		"apis/storage/v1beta1/bucket_hub.go": {
+			"func (*Bucket) Hub() {}",
+			"package/crds/storage.example.com_buckets.yaml",
+		},
+		"apis/storage/v1alpha1/bucket_conversion.go": {
+			`"github.com/example/provider-test/apis/storage/v1beta1"`,
+			"dst, ok := dstRaw.(*v1beta1.Bucket)",
+		},
+		"internal/controller/bucket/wiring.go": {
+			`"github.com/example/provider-test/apis/storage/v1beta1"`,
+			"ctrl.NewWebhookManagedBy(mgr, &v1beta1.Bucket{})",
+		},
+		"cmd/provider/main.go": {"webhook.NewServer(webhook.Options{CertDir: *certsDir})"},
+	} {
+		b, err := afero.ReadFile(mem, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(b), want) {
+				t.Errorf("%s is missing %q:\n%s", path, want, b)
+			}
+		}
+	}
+	types, _ := afero.ReadFile(mem, "apis/storage/v1alpha1/bucket_types.go")
+	if strings.Contains(string(types), storageVersionMarker) {
+		t.Error("a spoke's types carry the storage version marker")
+	}
+}
+
+func TestSetStorageMarker(t *testing.T) {
+	const src = `// +kubebuilder:object:root=true
+
+// A Bucket is an example API type.
+// +kubebuilder:subresource:status
+type Bucket struct {
+}
+
+type BucketList struct {
+}
+`
+	marked, err := setStorageMarker([]byte(src), kindBucket, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(marked), "status\n"+storageVersionMarker+"\ntype Bucket struct") {
+		t.Errorf("marker not placed next to the type:\n%s", marked)
+	}
+	again, _ := setStorageMarker(marked, kindBucket, true)
+	if string(again) != string(marked) {
+		t.Errorf("marking twice changed the file:\n%s", again)
+	}
+	unmarked, _ := setStorageMarker(marked, kindBucket, false)
+	if string(unmarked) != src {
+		t.Errorf("unmarking did not restore the file:\n%s", unmarked)
+	}
+	if _, err := setStorageMarker([]byte(src), "Instance", true); err == nil {
+		t.Error("want an error for a kind the file does not declare")
+	}
+}
+
+func TestCopyTypes(t *testing.T) {
+	got, err := copyTypes([]byte("// header\n\npackage v1alpha1\n\nvar x = v1alpha1ish\n"), "v1alpha1", "v1beta1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "// header\n\npackage v1beta1\n\nvar x = v1alpha1ish\n"; string(got) != want {
+		t.Errorf("copyTypes = %q, want %q", got, want)
+	}
+	if _, err := copyTypes([]byte("package v1\n"), "v1alpha1", "v1beta1"); err == nil {
+		t.Error("want an error for a file in another package")
+	}
+}
+
+func TestRetargetImport(t *testing.T) {
+	const (
+		from = "github.com/example/provider-test/apis/storage/v1alpha1"
+		to   = "github.com/example/provider-test/apis/storage/v1beta1"
+	)
+	src := `package bucket
+
+import (
+	"github.com/example/provider-test/apis/storage/v1alpha1"
+)
+
+func observe(cr *v1alpha1.Bucket) string { return cr.Spec.ForProvider.ConfigurableField }
+`
+	got, err := retargetImport([]byte(src), from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"` + to + `"`, "cr *v1beta1.Bucket"} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("retargeted file is missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(string(got), "v1alpha1") {
+		t.Errorf("retargeted file still names v1alpha1:\n%s", got)
+	}
+
+	unrelated := []byte("package bucket\n\nimport \"fmt\"\n\nvar _ = fmt.Sprint\n")
+	if got, _ := retargetImport(unrelated, from, to); string(got) != string(unrelated) {
+		t.Errorf("file without the import changed:\n%s", got)
+	}
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kubebuilder/v4/pkg/config"
+	"sigs.k8s.io/kubebuilder/v4/pkg/machinery"
+	"sigs.k8s.io/kubebuilder/v4/pkg/model/resource"
+	"sigs.k8s.io/kubebuilder/v4/pkg/plugin"
+
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/automation"
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/core"
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/validation"
+)
+
+var _ plugin.CreateWebhookSubcommand = &createWebhookSubcommand{}
+
+type createWebhookSubcommand struct {
+	Defaulting bool
+	Validation bool
+
+	config       config.Config
+	resource     *resource.Resource
+	pluginConfig *PluginConfig
+}
+
+func (p *createWebhookSubcommand) UpdateMetadata(cliMeta plugin.CLIMetadata, subcmdMeta *plugin.SubcommandMetadata) {
+	p.ensureConfig()
+
+	subcmdMeta.Description = `Add admission webhooks to a Crossplane managed resource.
+
+For checks the CRD's OpenAPI schema and CEL rules cannot express. This scaffolds:
+- internal/controller/<kind>/webhook.go, yours: Default, ValidateCreate,
+  ValidateUpdate and ValidateDelete
+- the webhook registration and +kubebuilder:webhook markers in the generated wiring.go
+- the provider's webhook server in cmd/provider/main.go
+
+'make generate' writes the webhook configurations to package/webhookconfigurations/,
+which Crossplane installs with the package, pointing them at the provider and
+provisioning its serving certificate.
+
+Conversion webhooks come with 'create-version' instead.`
+
+	subcmdMeta.Examples = fmt.Sprintf(`  # Validate Buckets beyond what the schema can say
+  %[1]s create webhook --group=storage --version=v1alpha1 --kind=Bucket --programmatic-validation
+
+  # Default and validate Instances
+  %[1]s create webhook --group=compute --version=v1alpha1 --kind=Instance --defaulting --programmatic-validation`,
+		cliMeta.CommandName)
+}
+
+func (p *createWebhookSubcommand) BindFlags(fs *pflag.FlagSet) {
+	p.ensureConfig()
+
+	fs.BoolVar(&p.Defaulting, "defaulting", false, "call Default in webhook.go before a resource is stored")
+	fs.BoolVar(&p.Validation, "programmatic-validation", false,
+		"call the Validate functions in webhook.go on create, update and delete")
+}
+
+// InjectConfig takes the project and the settings it was created with, which
+// the post-scaffolding commit runs with.
+func (p *createWebhookSubcommand) InjectConfig(c config.Config) error {
+	p.config = c
+	pluginConfig, err := loadPluginConfig(c)
+	if err != nil {
+		return validation.CreateWebhookError("generator settings", err)
+	}
+	p.pluginConfig = pluginConfig
+	return nil
+}
+
+// InjectResource swaps the resource named on the command line for the project's
+// own, with the requested webhooks added to those it already has.
+func (p *createWebhookSubcommand) InjectResource(res *resource.Resource) error {
+	if !p.Defaulting && !p.Validation {
+		return validation.CreateWebhookError("flag check",
+			fmt.Errorf("pass --defaulting, --programmatic-validation or both"))
+	}
+
+	existing, err := p.config.GetResources()
+	if err != nil {
+		return validation.CreateWebhookError("reading project resources", err)
+	}
+	var found *resource.Resource
+	for i := range existing {
+		r := existing[i]
+		if r.Group == res.Group && r.Version == res.Version && r.Kind == res.Kind {
+			found = &r
+		}
+	}
+	if found == nil {
+		return validation.CreateWebhookError("resource check",
+			fmt.Errorf("%s/%s %s is not in this project; create it with `create api` first",
+				res.Group, res.Version, res.Kind))
+	}
+
+	settings, err := core.LoadProjectSettings(p.config)
+	if err != nil {
+		return err
+	}
+	if hub := settings.StorageVersion(*found); hub != "" && hub != found.Version {
+		return validation.CreateWebhookError("resource check",
+			fmt.Errorf("%s is served in several versions and its webhooks run against the storage version; "+
+				"use --version=%s", found.Kind, hub))
+	}
+
+	if found.Webhooks == nil {
+		found.Webhooks = &resource.Webhooks{}
+	}
+	if p.Defaulting && found.Webhooks.Defaulting {
+		return validation.CreateWebhookError("resource check", fmt.Errorf("%s already has a defaulting webhook", found.Kind))
+	}
+	if p.Validation && found.Webhooks.Validation {
+		return validation.CreateWebhookError("resource check", fmt.Errorf("%s already has a validating webhook", found.Kind))
+	}
+	found.Webhooks.WebhookVersion = "v1"
+	found.Webhooks.Defaulting = found.Webhooks.Defaulting || p.Defaulting
+	found.Webhooks.Validation = found.Webhooks.Validation || p.Validation
+
+	*res = *found
+	p.resource = res
+	return nil
+}
+
+func (p *createWebhookSubcommand) PreScaffold(machinery.Filesystem) error {
+	return nil
+}
+
+// Scaffold records the webhooks in PROJECT, then re-renders the kind through the
+// ownership gate: the seam is seeded, and the generated wiring and main.go pick
+// the webhooks up. Kubebuilder saves PROJECT once this returns.
+func (p *createWebhookSubcommand) Scaffold(fs machinery.Filesystem) error {
+	fmt.Printf("Adding admission webhooks to %s/%s %s\n",
+		p.resource.Group, p.resource.Version, p.resource.Kind)
+
+	if err := p.config.UpdateResource(*p.resource); err != nil {
+		return validation.CreateWebhookError("PROJECT update", err)
+	}
+	result, err := refreshKind(p.config, fs.FS, p.resource.Group, p.resource.Kind)
+	if err != nil {
+		return validation.CreateWebhookError("scaffolding", err)
+	}
+	result.print()
+	return nil
+}
+
+func (p *createWebhookSubcommand) PostScaffold() error {
+	p.ensureConfig()
+
+	pipeline := automation.NewWebhookCommitPipeline(p.pluginConfig, p.resource.Kind)
+	fmt.Println("Running post-scaffolding automation...")
+	if err := pipeline.Run(); err != nil {
+		return validation.CreateWebhookError("post-scaffolding automation", err)
+	}
+
+	fmt.Printf("Admission webhooks for %s created successfully!\n", p.resource.Kind)
+	fmt.Printf("Next: implement the checks in internal/controller/%s/webhook.go\n",
+		strings.ToLower(p.resource.Kind))
+
+	return nil
+}
+
+func (p *createWebhookSubcommand) ensureConfig() {
+	if p.pluginConfig == nil {
+		p.pluginConfig = NewPluginConfig()
+	}
+}
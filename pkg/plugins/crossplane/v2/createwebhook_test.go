@@ -0,0 +1,190 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kubebuilder/v4/pkg/config"
+	cfgv3 "sigs.k8s.io/kubebuilder/v4/pkg/config/v3"
+	"sigs.k8s.io/kubebuilder/v4/pkg/machinery"
+	"sigs.k8s.io/kubebuilder/v4/pkg/model/resource"
+)
+
+// webhookProject is a provider with a single Bucket kind carrying the given webhooks.
+func webhookProject(t *testing.T, webhooks *resource.Webhooks) config.Config {
+	t.Helper()
+	cfg := cfgv3.New()
+	_ = cfg.SetRepository("github.com/example/provider-test")
+	_ = cfg.SetDomain("example.com")
+	_ = cfg.SetProjectName("provider-test")
+	res := resource.Resource{
+		GVK:        resource.GVK{Group: "storage", Version: "v1alpha1", Kind: kindBucket, Domain: "example.com"},
+		Plural:     "buckets",
+		Path:       "github.com/example/provider-test/apis/storage/v1alpha1",
+		API:        &resource.API{CRDVersion: "v1", Namespaced: true},
+		Controller: true,
+		Webhooks:   webhooks,
+	}
+	if err := cfg.AddResource(res); err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestCreateWebhookInjectResource(t *testing.T) {
+	cases := map[string]struct {
+		cfg            func(t *testing.T) config.Config
+		args           []string
+		version        string
+		wantErr        string
+		wantDefaulting bool
+		wantValidation bool
+	}{
+		"NoFlags": {
+			cfg:     func(t *testing.T) config.Config { return webhookProject(t, nil) },
+			version: "v1alpha1",
+			wantErr: "pass --defaulting",
+		},
+		"UnknownKind": {
+			cfg:     func(t *testing.T) config.Config { return webhookProject(t, nil) },
+			args:    []string{"--defaulting"},
+			version: "v1beta1",
+			wantErr: "create it with `create api` first",
+		},
+		"SpokeVersion": {
+			cfg:     multiVersionProject,
+			args:    []string{"--defaulting"},
+			version: "v1alpha1",
+			wantErr: "use --version=v1beta1",
+		},
+		"AlreadyDefaulting": {
+			cfg: func(t *testing.T) config.Config {
+				return webhookProject(t, &resource.Webhooks{WebhookVersion: "v1", Defaulting: true})
+			},
+			args:    []string{"--defaulting"},
+			version: "v1alpha1",
+			wantErr: "already has a defaulting webhook",
+		},
+		"AddsValidation": {
+			cfg: func(t *testing.T) config.Config {
+				return webhookProject(t, &resource.Webhooks{WebhookVersion: "v1", Defaulting: true})
+			},
+			args:           []string{"--programmatic-validation"},
+			version:        "v1alpha1",
+			wantDefaulting: true,
+			wantValidation: true,
+		},
+		"StorageVersion": {
+			cfg:            multiVersionProject,
+			args:           []string{"--defaulting"},
+			version:        "v1beta1",
+			wantDefaulting: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := &createWebhookSubcommand{}
+			fs := pflag.NewFlagSet("create webhook", pflag.ContinueOnError)
+			p.BindFlags(fs)
+			if err := fs.Parse(tc.args); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.InjectConfig(tc.cfg(t)); err != nil {
+				t.Fatal(err)
+			}
+
+			res := &resource.Resource{GVK: resource.GVK{Group: "storage", Version: tc.version, Kind: kindBucket}}
+			err := p.InjectResource(res)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("InjectResource() error = %v, want one containing %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Plural != "buckets" {
+				t.Errorf("resource was not taken from the project: %+v", res)
+			}
+			if res.Webhooks.Defaulting != tc.wantDefaulting || res.Webhooks.Validation != tc.wantValidation {
+				t.Errorf("Webhooks = %+v, want defaulting %v, validation %v",
+					res.Webhooks, tc.wantDefaulting, tc.wantValidation)
+			}
+		})
+	}
+}
+
+func TestRenderAdmissionWebhooks(t *testing.T) {
+	mem := afero.NewMemMapFs()
+	cfg := webhookProject(t, &resource.Webhooks{WebhookVersion: "v1", Defaulting: true, Validation: true})
+	if err := renderToMemFS(cfg, machinery.Filesystem{FS: mem}); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+
+	for path, wants := range map[string][]string{
+		"internal/controller/bucket/webhook.go": {
+			"func Default(_ context.Context, _ *v1alpha1.Bucket) error",
+			"func ValidateUpdate(_ context.Context, _, _ *v1alpha1.Bucket) (admission.Warnings, error)",
+		},
+		"internal/controller/bucket/wiring.go": {
+			"ctrl.NewWebhookManagedBy(mgr, &v1alpha1.Bucket{})",
+			"WithDefaulter(admissionHooks{}).\n",
+			"WithValidator(admissionHooks{}).\n",
+			"+kubebuilder:webhook:path=/mutate-storage-example-com-v1alpha1-bucket,",
+			"+kubebuilder:webhook:path=/validate-storage-example-com-v1alpha1-bucket,",
+		},
+		"cmd/provider/main.go": {"webhook.NewServer(webhook.Options{CertDir: *certsDir})"},
+	} {
+		b, err := afero.ReadFile(mem, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if path == "internal/controller/bucket/webhook.go" && strings.Contains(string(b), "DO NOT EDIT") {
+			t.Errorf("%s is user-owned but carries the generated header", path)
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(b), want) {
+				t.Errorf("%s is missing %q:\n%s", path, want, b)
+			}
+		}
+	}
+}
+
+func TestRenderWithoutWebhooks(t *testing.T) {
+	mem := afero.NewMemMapFs()
+	if err := renderToMemFS(webhookProject(t, nil), machinery.Filesystem{FS: mem}); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+
+	if exists, _ := afero.Exists(mem, "internal/controller/bucket/webhook.go"); exists {
+		t.Error("webhook.go rendered for a kind without webhooks")
+	}
+	for _, path := range []string{"internal/controller/bucket/wiring.go", "cmd/provider/main.go"} {
+		b, err := afero.ReadFile(mem, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(b), "webhook") {
+			t.Errorf("%s mentions webhooks for a kind without any:\n%s", path, b)
+		}
+	}
+}
@@ -0,0 +1,466 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kubebuilder/v4/pkg/config"
+	"sigs.k8s.io/kubebuilder/v4/pkg/machinery"
+	"sigs.k8s.io/kubebuilder/v4/pkg/model/resource"
+
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/automation"
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/core"
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/templates/engine"
+)
+
+// NewDeleteCommand returns the `delete` command, registered on the CLI via
+// cli.WithExtraCommands (kubebuilder's plugin interface has no delete hook).
+func NewDeleteCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete",
+		Short: "Remove scaffolded resources from the project",
+	}
+	cmd.AddCommand(newDeleteAPICommand())
+	return cmd
+}
+
+// deleteAPIOptions are the flags of `delete api`.
+type deleteAPIOptions struct {
+	group, version, kind string
+	purge                bool
+	keep                 bool
+}
+
+func newDeleteAPICommand() *cobra.Command {
+	var opts deleteAPIOptions
+	cmd := &cobra.Command{
+		Use:   "api",
+		Short: "Remove a managed resource kind",
+		Long: `Remove a managed resource kind created with 'create api'.
+
+The kind is dropped from PROJECT, the registration files are regenerated without it,
+and its tool-owned files (wiring.go) are deleted, along with the integration_test.go
+that tests them. Its other user-owned files (*_types.go, external.go, the example
+manifest, the e2e and behavior tests) are listed but kept, since they may hold work you
+want to move elsewhere; pass --purge to delete them too. An API package left with
+no kind is marked +kubebuilder:skip, so the kept types make no CRD.
+
+The working tree must be clean, so the removal lands as one reviewable commit and
+anything deleted stays recoverable from history. If any step fails, PROJECT and every
+file are put back as they were; pass --keep-on-failure to leave the failed state in place
+instead.`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return runDeleteAPI(context.Background(), opts, os.Stdout)
+		},
+	}
+	cmd.Flags().StringVar(&opts.group, "group", "", "resource group")
+	cmd.Flags().StringVar(&opts.version, "version", "", "resource version")
+	cmd.Flags().StringVar(&opts.kind, "kind", "", "resource kind")
+	cmd.Flags().BoolVar(&opts.purge, "purge", false, "also delete the kind's user-owned files")
+	cmd.Flags().BoolVar(&opts.keep, "keep-on-failure", false,
+		"leave the changes of a failed removal in place for debugging instead of rolling them back")
+	for _, name := range []string{"group", "version", "kind"} {
+		_ = cmd.MarkFlagRequired(name)
+	}
+	return cmd
+}
+
+// runDeleteAPI removes the kind opts names. PROJECT is saved before the kind's
+// files are deleted, so a failure part way is rolled back like update's.
+func runDeleteAPI(ctx context.Context, opts deleteAPIOptions, out io.Writer) error {
+	return withRollback(ctx, opts.keep, func(context.Context) error {
+		return deleteAPI(opts, out)
+	})
+}
+
+func deleteAPI(opts deleteAPIOptions, out io.Writer) error {
+	st, err := loadProjectStore()
+	if err != nil {
+		return err
+	}
+	cfg := st.Config()
+	pluginConfig, err := loadPluginConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	res, remaining, err := splitResource(cfg, opts)
+	if err != nil {
+		return err
+	}
+	settings, err := core.LoadProjectSettings(cfg)
+	if err != nil {
+		return err
+	}
+	siblings := otherVersions(res, remaining)
+	hub := settings.StorageVersion(res)
+	if hub == res.Version && len(siblings) > 0 {
+		return fmt.Errorf("%s is the storage version of %s, which is still served in %s; "+
+			"delete those versions first", res.Version, res.Kind, strings.Join(siblings, ", "))
+	}
+
+	disk := trackWrites(afero.NewOsFs())
+	plan, err := planDeletion(cfg, res, remaining, disk, opts.purge)
+	if err != nil {
+		return fmt.Errorf("planning removal of %s: %w", res.Kind, err)
+	}
+
+	// Down to one version, the kind no longer converts: its hub loses Hub(), the
+	// storage version marker and the webhook registration in wiring.go.
+	single := hub != "" && len(siblings) == 1
+	if single {
+		settings.SetStorageVersion(res, "")
+		if err := core.SaveProjectSettings(cfg, settings); err != nil {
+			return err
+		}
+		if exists, _ := afero.Exists(disk, apiFile(res, hub, "_hub.go")); exists {
+			plan.remove = append(plan.remove, apiFile(res, hub, "_hub.go"))
+		}
+	}
+	// The last version of the kind takes its connection keys, external-name
+	// strategy and fake API with it.
+	if len(siblings) == 0 &&
+		(settings.ConnectionKeys(res) != nil || settings.ExternalName(res) != "" || settings.FakeAPI(res)) {
+		settings.SetConnectionKeys(res, nil)
+		settings.SetExternalName(res, "")
+		settings.SetFakeAPI(res, false)
+		if err := core.SaveProjectSettings(cfg, settings); err != nil {
+			return err
+		}
+	}
+	if err := core.NewProjectFile(cfg).RemoveResource(res.GVK); err != nil {
+		return fmt.Errorf("removing %s from PROJECT: %w", res.Kind, err)
+	}
+	scaffold := machinery.NewScaffold(machinery.Filesystem{FS: disk},
+		machinery.WithConfig(cfg),
+		machinery.WithBoilerplate(engine.ProjectBoilerplate(cfg)),
+	)
+	if err := scaffold.Execute(engine.CoreGenerators(cfg, remaining)...); err != nil {
+		return fmt.Errorf("regenerating registration files: %w", err)
+	}
+	if err := plan.apply(disk); err != nil {
+		return fmt.Errorf("deleting %s files: %w", res.Kind, err)
+	}
+	if single {
+		if err := rewriteFile(disk, apiFile(res, hub, "_types.go"), func(b []byte) ([]byte, error) {
+			return setStorageMarker(b, res.Kind, false)
+		}); err != nil {
+			return err
+		}
+		if _, err := refreshKind(cfg, disk, res.Group, res.Kind); err != nil {
+			return fmt.Errorf("refreshing %s %s: %w", res.Kind, hub, err)
+		}
+	}
+	if err := disk.saveLock(); err != nil {
+		return err
+	}
+	plan.print(out)
+
+	pipeline := automation.NewAPIDeletePipeline(pluginConfig, res.Kind)
+	fmt.Fprintln(out, "Running post-deletion automation...")
+	if err := pipeline.Run(); err != nil {
+		return fmt.Errorf("post-deletion automation: %w", err)
+	}
+	fmt.Fprintf(out, "Crossplane managed resource %s removed.\n", res.Kind)
+	return nil
+}
+
+// splitResource finds the resource named by opts and returns it together with the
+// project's other resources.
+func splitResource(cfg config.Config, opts deleteAPIOptions) (resource.Resource, []resource.Resource, error) {
+	all, err := cfg.GetResources()
+	if err != nil {
+		return resource.Resource{}, nil, fmt.Errorf("reading project resources: %w", err)
+	}
+	var (
+		found     *resource.Resource
+		remaining []resource.Resource
+	)
+	for i := range all {
+		r := all[i]
+		if r.Group == opts.group && r.Version == opts.version && r.Kind == opts.kind {
+			found = &r
+			continue
+		}
+		remaining = append(remaining, r)
+	}
+	if found == nil {
+		return resource.Resource{}, nil, fmt.Errorf("%s/%s %s is not in this project",
+			opts.group, opts.version, opts.kind)
+	}
+	return *found, remaining, nil
+}
+
+// otherVersions returns the versions res's kind is served in besides res's own.
+func otherVersions(res resource.Resource, remaining []resource.Resource) []string {
+	var versions []string
+	for _, r := range remaining {
+		if r.Group == res.Group && r.Kind == res.Kind {
+			versions = append(versions, r.Version)
+		}
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// deletionPlan splits the on-disk files that belong to one kind alone into those
+// to delete and those to keep.
+type deletionPlan struct {
+	remove []string
+	keep   []string
+	// skip are the kept groupversion_info.go files of API packages no kind in
+	// PROJECT is left in. apply marks them so `make generate` makes no CRDs
+	// from the kept types.
+	skip []string
+}
+
+// planDeletion works out which files belong to res alone: those its API templates
+// render that no remaining resource also renders (a group/version shared with
+// another kind keeps its groupversion_info.go). Files already gone from disk are
+// ignored.
+//
+// Without purge, user-owned files are kept, and so is groupversion_info.go in an
+// API package whose *_types.go is kept, since the types cannot compile without it.
+// Likewise a kept external.go keeps the zz_compare.go and zz_connection.go it
+// calls, and the kind's fake API; it needs nothing from wiring.go, so wiring.go
+// always goes, and with it the integration_test.go that tests the controller it
+// sets up. A kept groupversion_info.go is also planned for skipCRDs, since its
+// package has no kind left. The zz_generated files of each API package that loses Go files are
+// deleted too; they name the removed type and `make generate` recreates them.
+func planDeletion(cfg config.Config, res resource.Resource, remaining []resource.Resource,
+	disk afero.Fs, purge bool,
+) (deletionPlan, error) {
+	owned, err := ownedFiles(cfg, res, remaining, disk)
+	if err != nil {
+		return deletionPlan{}, err
+	}
+
+	keepDirs := map[string]bool{}
+	for rel, toolOwned := range owned {
+		if !toolOwned && !purge && strings.HasSuffix(rel, ".go") && !testsWiring(rel) {
+			keepDirs[path.Dir(rel)] = true
+		}
+	}
+
+	var plan deletionPlan
+	apiDirs := map[string]bool{}
+	for rel, toolOwned := range owned {
+		apiGo := isAPIGoFile(rel)
+		caller, called := calledFrom(rel)
+		kept := (!toolOwned && !purge && !testsWiring(rel)) ||
+			(apiGo && keepDirs[path.Dir(rel)]) || (called && keepDirs[caller])
+		if kept {
+			plan.keep = append(plan.keep, rel)
+			if toolOwned && path.Base(rel) == "groupversion_info.go" {
+				plan.skip = append(plan.skip, rel)
+			}
+			continue
+		}
+		plan.remove = append(plan.remove, rel)
+		if apiGo {
+			apiDirs[path.Dir(rel)] = true
+		}
+	}
+	for dir := range apiDirs {
+		matches, err := afero.Glob(disk, path.Join(dir, "zz_generated.*.go"))
+		if err != nil {
+			return deletionPlan{}, err
+		}
+		plan.remove = append(plan.remove, matches...)
+	}
+	sort.Strings(plan.remove)
+	sort.Strings(plan.keep)
+	sort.Strings(plan.skip)
+	return plan, nil
+}
+
+// calledByExternal are the tool-owned files of a kind's controller package that
+// its external.go calls.
+var calledByExternal = map[string]bool{"zz_compare.go": true, "zz_connection.go": true}
+
+// calledFrom returns the controller package whose external.go calls the
+// tool-owned file rel, if one does: a file of calledByExternal beside it, or
+// the kind's fake API in internal/fake/<kind>.
+func calledFrom(rel string) (string, bool) {
+	dir := path.Dir(rel)
+	if path.Dir(dir) == "internal/fake" {
+		return path.Join("internal/controller", path.Base(dir)), true
+	}
+	return dir, calledByExternal[path.Base(rel)]
+}
+
+// testsWiring reports whether rel is the user-owned integration test of a
+// kind's controller package, which calls setup in its wiring.go.
+func testsWiring(rel string) bool {
+	return path.Dir(path.Dir(rel)) == "internal/controller" && path.Base(rel) == "integration_test.go"
+}
+
+func isAPIGoFile(rel string) bool {
+	return strings.HasPrefix(rel, "apis/") && strings.HasSuffix(rel, ".go")
+}
+
+// ownedFiles maps each on-disk file rendered for res and for no remaining
+// resource to whether its on-disk copy is tool-owned.
+func ownedFiles(cfg config.Config, res resource.Resource, remaining []resource.Resource,
+	disk afero.Fs,
+) (map[string]bool, error) {
+	factory := engine.NewFactory(cfg)
+	own, err := renderedPaths(cfg, factory, res)
+	if err != nil {
+		return nil, err
+	}
+	shared := map[string]bool{}
+	for _, r := range remaining {
+		paths, err := renderedPaths(cfg, factory, r)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range paths {
+			shared[p] = true
+		}
+	}
+
+	owned := map[string]bool{}
+	for _, rel := range own {
+		if shared[rel] {
+			continue
+		}
+		if err := checkContained(rel); err != nil {
+			return nil, err
+		}
+		exists, content, err := readExisting(disk, rel)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			owned[rel] = core.IsToolOwned(content)
+		}
+	}
+	return owned, nil
+}
+
+// renderedPaths returns the slash-separated paths res's API templates render to.
+func renderedPaths(cfg config.Config, factory engine.TemplateFactory, res resource.Resource) ([]string, error) {
+	mem := afero.NewMemMapFs()
+	if err := renderAPIToMemFS(cfg, factory, res, machinery.Filesystem{FS: mem}); err != nil {
+		return nil, err
+	}
+	var paths []string
+	err := afero.Walk(mem, ".", func(p string, info fs.FileInfo, walkErr error) error {
+		if walkErr != nil || info.IsDir() {
+			return walkErr
+		}
+		paths = append(paths, strings.TrimPrefix(filepath.ToSlash(p), "/"))
+		return nil
+	})
+	return paths, err
+}
+
+// apply deletes the planned files and then any directories that left empty,
+// and marks the API packages to skip.
+func (d deletionPlan) apply(disk afero.Fs) error {
+	for _, rel := range d.remove {
+		if err := disk.Remove(rel); err != nil {
+			return err
+		}
+	}
+	for _, rel := range d.remove {
+		if err := removeEmptyParents(disk, path.Dir(rel)); err != nil {
+			return err
+		}
+	}
+	for _, rel := range d.skip {
+		if err := rewriteFile(disk, rel, skipCRDs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipMarker keeps controller-gen from making CRDs for the types of an API
+// package. Their deepcopy and managed methods are still generated, so they
+// compile.
+const skipMarker = "// +kubebuilder:skip"
+
+// skipCRDs returns a groupversion_info.go with skipMarker last in its package
+// comment.
+func skipCRDs(src []byte) ([]byte, error) {
+	lines := strings.Split(string(src), "\n")
+	for i, l := range lines {
+		if strings.TrimSpace(l) == skipMarker {
+			return src, nil
+		}
+		if strings.HasPrefix(l, "package ") {
+			lines = append(lines[:i], append([]string{skipMarker}, lines[i:]...)...)
+			return []byte(strings.Join(lines, "\n")), nil
+		}
+	}
+	return nil, fmt.Errorf("no package clause")
+}
+
+// removeEmptyParents removes dir and then each parent in turn while they are
+// empty, stopping at the project root.
+func removeEmptyParents(disk afero.Fs, dir string) error {
+	for ; dir != "." && dir != "/"; dir = path.Dir(dir) {
+		entries, err := afero.ReadDir(disk, dir)
+		if os.IsNotExist(err) {
+			continue // already removed via another file's walk
+		}
+		if err != nil {
+			return err
+		}
+		if len(entries) > 0 {
+			return nil
+		}
+		if err := disk.Remove(dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d deletionPlan) print(out io.Writer) {
+	fmt.Fprintf(out, "Deleted %d file(s):\n", len(d.remove))
+	printPaths(out, d.remove)
+	if len(d.keep) > 0 {
+		fmt.Fprintf(out, "Kept %d file(s) holding or building with your code; "+
+			"delete them yourself or re-run with --purge:\n", len(d.keep))
+		printPaths(out, d.keep)
+	}
+	if len(d.skip) > 0 {
+		fmt.Fprintf(out, "Marked %d kept API package(s) +kubebuilder:skip, so `make generate` "+
+			"makes no CRD from their types:\n", len(d.skip))
+		printPaths(out, d.skip)
+	}
+}
+
+func printPaths(out io.Writer, paths []string) {
+	for _, p := range paths {
+		fmt.Fprintf(out, "  - %s\n", p)
+	}
+}
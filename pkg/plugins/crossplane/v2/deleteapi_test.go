@@ -0,0 +1,307 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"sigs.k8s.io/kubebuilder/v4/pkg/config"
+	cfgv3 "sigs.k8s.io/kubebuilder/v4/pkg/config/v3"
+	"sigs.k8s.io/kubebuilder/v4/pkg/machinery"
+	"sigs.k8s.io/kubebuilder/v4/pkg/model/resource"
+
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/core"
+)
+
+// deleteTestProject renders a provider with two kinds sharing compute/v1alpha1 and
+// one alone in storage/v1alpha1 onto an in-memory "disk", with a zz_generated file
+// in each API package as `make generate` would leave.
+func deleteTestProject(t *testing.T) (config.Config, afero.Fs) {
+	t.Helper()
+	cfg := cfgv3.New()
+	_ = cfg.SetRepository("github.com/example/provider-test")
+	_ = cfg.SetDomain("example.com")
+	_ = cfg.SetProjectName("provider-test")
+	for _, gvk := range []resource.GVK{
+		{Group: "compute", Version: "v1alpha1", Kind: "Instance", Domain: "example.com"},
+		{Group: "compute", Version: "v1alpha1", Kind: "Disk", Domain: "example.com"},
+		{Group: "storage", Version: "v1alpha1", Kind: kindBucket, Domain: "example.com"},
+	} {
+		res := resource.Resource{
+			GVK:        gvk,
+			Path:       "github.com/example/provider-test/apis/" + gvk.Group + "/" + gvk.Version,
+			API:        &resource.API{CRDVersion: "v1", Namespaced: true},
+			Controller: true,
+		}
+		if err := cfg.AddResource(res); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	disk := afero.NewMemMapFs()
+	if err := renderToMemFS(cfg, machinery.Filesystem{FS: disk}); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	for _, p := range []string{
+		"apis/compute/v1alpha1/zz_generated.deepcopy.go",
+		"apis/storage/v1alpha1/zz_generated.deepcopy.go",
+	} {
+		_ = afero.WriteFile(disk, p, []byte("package v1alpha1\n"), 0o644)
+	}
+	return cfg, disk
+}
+
+func planFor(t *testing.T, cfg config.Config, disk afero.Fs, kind string, purge bool) deletionPlan {
+	t.Helper()
+	all, _ := cfg.GetResources()
+	var res resource.Resource
+	var remaining []resource.Resource
+	for _, r := range all {
+		if r.Kind == kind {
+			res = r
+		} else {
+			remaining = append(remaining, r)
+		}
+	}
+	plan, err := planDeletion(cfg, res, remaining, disk, purge)
+	if err != nil {
+		t.Fatalf("planDeletion: %v", err)
+	}
+	return plan
+}
+
+func TestPlanDeletion(t *testing.T) {
+	cases := map[string]struct {
+		kind       string
+		purge      bool
+		wantRemove []string
+		wantKeep   []string
+		wantSkip   []string
+	}{
+		"keeps user files": {
+			kind: "Instance",
+			wantRemove: []string{
+				"internal/controller/instance/integration_test.go",
+				"internal/controller/instance/wiring.go",
+			},
+			wantKeep: []string{
+				"apis/compute/v1alpha1/instance_types.go",
+				"examples/compute/instance.yaml",
+				"internal/controller/instance/external.go",
+				"internal/controller/instance/external_test.go",
+				"internal/controller/instance/zz_compare.go",
+				"test/behavior/instance-pause/chainsaw-test.yaml",
+				"test/e2e/instance-lifecycle.yaml",
+			},
+		},
+		"keeps the files kept code compiles against": {
+			kind: kindBucket,
+			wantRemove: []string{
+				"internal/controller/bucket/integration_test.go",
+				"internal/controller/bucket/wiring.go",
+			},
+			wantKeep: []string{
+				"apis/storage/v1alpha1/bucket_types.go",
+				"apis/storage/v1alpha1/groupversion_info.go",
+				"examples/storage/bucket.yaml",
+				"internal/controller/bucket/external.go",
+				"internal/controller/bucket/external_test.go",
+				"internal/controller/bucket/zz_compare.go",
+				"test/behavior/bucket-pause/chainsaw-test.yaml",
+				"test/e2e/bucket-lifecycle.yaml",
+			},
+			wantSkip: []string{"apis/storage/v1alpha1/groupversion_info.go"},
+		},
+		"purge spares the group version another kind shares": {
+			kind:  "Instance",
+			purge: true,
+			wantRemove: []string{
+				"apis/compute/v1alpha1/instance_types.go",
+				"apis/compute/v1alpha1/zz_generated.deepcopy.go",
+				"examples/compute/instance.yaml",
+				"internal/controller/instance/external.go",
+				"internal/controller/instance/external_test.go",
+				"internal/controller/instance/integration_test.go",
+				"internal/controller/instance/wiring.go",
+				"internal/controller/instance/zz_compare.go",
+				"test/behavior/instance-pause/chainsaw-test.yaml",
+				"test/e2e/instance-lifecycle.yaml",
+			},
+		},
+		"purge removes a group version the kind had alone": {
+			kind:  kindBucket,
+			purge: true,
+			wantRemove: []string{
+				"apis/storage/v1alpha1/bucket_types.go",
+				"apis/storage/v1alpha1/groupversion_info.go",
+				"apis/storage/v1alpha1/zz_generated.deepcopy.go",
+				"examples/storage/bucket.yaml",
+				"internal/controller/bucket/external.go",
+				"internal/controller/bucket/external_test.go",
+				"internal/controller/bucket/integration_test.go",
+				"internal/controller/bucket/wiring.go",
+				"internal/controller/bucket/zz_compare.go",
+				"test/behavior/bucket-pause/chainsaw-test.yaml",
+				"test/e2e/bucket-lifecycle.yaml",
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cfg, disk := deleteTestProject(t)
+			plan := planFor(t, cfg, disk, tc.kind, tc.purge)
+			if !slices.Equal(plan.remove, tc.wantRemove) {
+				t.Errorf("remove = %v\nwant     %v", plan.remove, tc.wantRemove)
+			}
+			if !slices.Equal(plan.keep, tc.wantKeep) {
+				t.Errorf("keep = %v\nwant   %v", plan.keep, tc.wantKeep)
+			}
+			if !slices.Equal(plan.skip, tc.wantSkip) {
+				t.Errorf("skip = %v, want %v", plan.skip, tc.wantSkip)
+			}
+		})
+	}
+}
+
+// TestPlanDeletion_KindSettings covers the files a kind's settings add to it:
+// those its kept external.go needs stay unless purged.
+func TestPlanDeletion_KindSettings(t *testing.T) {
+	cases := map[string]struct {
+		set        func(*core.ProjectSettings, resource.Resource)
+		wantKeep   []string
+		wantRemove []string
+		// wantPurged are removed with --purge.
+		wantPurged []string
+	}{
+		"ConnectionKeys": {
+			set:        func(s *core.ProjectSettings, r resource.Resource) { s.SetConnectionKeys(r, []string{"endpoint"}) },
+			wantKeep:   []string{"internal/controller/bucket/zz_connection.go"},
+			wantRemove: []string{"test/e2e/bucket-connection.sh"},
+			wantPurged: []string{"internal/controller/bucket/zz_connection.go", "test/e2e/bucket-connection.sh"},
+		},
+		"ImportTest": {
+			set:        func(s *core.ProjectSettings, r resource.Resource) { s.SetExternalName(r, "identifier") },
+			wantKeep:   []string{"test/behavior/bucket-import/chainsaw-test.yaml"},
+			wantPurged: []string{"test/behavior/bucket-import/chainsaw-test.yaml"},
+		},
+		"FakeAPI": {
+			set:        func(s *core.ProjectSettings, r resource.Resource) { s.SetFakeAPI(r, true) },
+			wantKeep:   []string{"internal/fake/bucket/client.go", "internal/fake/bucket/server.go"},
+			wantPurged: []string{"internal/fake/bucket/client.go", "internal/fake/bucket/server.go"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cfg, disk := deleteTestProject(t)
+			all, _ := cfg.GetResources()
+			settings, _ := core.LoadProjectSettings(cfg)
+			for _, r := range all {
+				if r.Kind == kindBucket {
+					tc.set(&settings, r)
+				}
+			}
+			if err := core.SaveProjectSettings(cfg, settings); err != nil {
+				t.Fatal(err)
+			}
+			if err := renderToMemFS(cfg, machinery.Filesystem{FS: disk}); err != nil {
+				t.Fatalf("render: %v", err)
+			}
+
+			plan := planFor(t, cfg, disk, kindBucket, false)
+			for _, p := range tc.wantKeep {
+				if !slices.Contains(plan.keep, p) {
+					t.Errorf("keep = %v, want %s", plan.keep, p)
+				}
+			}
+			for _, p := range tc.wantRemove {
+				if !slices.Contains(plan.remove, p) {
+					t.Errorf("remove = %v, want %s", plan.remove, p)
+				}
+			}
+			plan = planFor(t, cfg, disk, kindBucket, true)
+			for _, p := range tc.wantPurged {
+				if !slices.Contains(plan.remove, p) {
+					t.Errorf("purge: remove = %v, want %s", plan.remove, p)
+				}
+			}
+		})
+	}
+}
+
+func TestDeletionPlanApply_PrunesEmptyDirs(t *testing.T) {
+	cfg, disk := deleteTestProject(t)
+	plan := planFor(t, cfg, disk, kindBucket, true)
+	if err := plan.apply(disk); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+
+	for _, gone := range []string{"apis/storage", "internal/controller/bucket", "test/behavior/bucket-pause"} {
+		if exists, _ := afero.Exists(disk, gone); exists {
+			t.Errorf("%s should have been removed once empty", gone)
+		}
+	}
+	for _, kept := range []string{"apis/compute/v1alpha1/disk_types.go", "test/e2e/disk-lifecycle.yaml"} {
+		if exists, _ := afero.Exists(disk, kept); !exists {
+			t.Errorf("%s belongs to another kind and must survive", kept)
+		}
+	}
+}
+
+func TestDeletionPlanApply_SkipsCRDs(t *testing.T) {
+	cfg, disk := deleteTestProject(t)
+	plan := planFor(t, cfg, disk, kindBucket, false)
+	if err := plan.apply(disk); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	// Marking a package again leaves one marker.
+	if err := (deletionPlan{skip: plan.skip}).apply(disk); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+
+	b, _ := afero.ReadFile(disk, "apis/storage/v1alpha1/groupversion_info.go")
+	if !strings.Contains(string(b), "// +versionName=v1alpha1\n"+skipMarker+"\npackage v1alpha1\n") {
+		t.Errorf("groupversion_info.go does not skip CRDs in its package comment:\n%s", b)
+	}
+	if strings.Count(string(b), skipMarker) != 1 {
+		t.Errorf("groupversion_info.go has %d skip markers, want 1", strings.Count(string(b), skipMarker))
+	}
+	if !core.IsToolOwned(b) {
+		t.Error("groupversion_info.go is no longer tool-owned")
+	}
+	if b, _ := afero.ReadFile(disk, "apis/compute/v1alpha1/groupversion_info.go"); strings.Contains(string(b), skipMarker) {
+		t.Error("the group version other kinds are left in skips CRDs")
+	}
+}
+
+func TestSplitResource(t *testing.T) {
+	cfg, _ := deleteTestProject(t)
+
+	res, remaining, err := splitResource(cfg, deleteAPIOptions{group: "compute", version: "v1alpha1", kind: "Disk"})
+	if err != nil {
+		t.Fatalf("splitResource: %v", err)
+	}
+	if res.Kind != "Disk" || len(remaining) != 2 {
+		t.Errorf("got %s with %d remaining, want Disk with 2", res.Kind, len(remaining))
+	}
+
+	if _, _, err := splitResource(cfg, deleteAPIOptions{group: "compute", version: "v1", kind: "Disk"}); err == nil {
+		t.Error("want an error for a kind that is not in the project")
+	}
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change, as
+// `diff -u` and `git diff` do by default.
+const diffContext = 3
+
+// diffOp is one line of an edit script: kept (' '), removed ('-') or added ('+').
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// unifiedDiff renders the change from before to after in unified diff format under
+// the given file labels. It returns "" when the contents are identical. Rendered
+// files are small, so a plain LCS table is used rather than Myers' algorithm.
+func unifiedDiff(fromLabel, toLabel string, before, after []byte) string {
+	ops := editScript(splitLines(string(before)), splitLines(string(after)))
+	hunks := groupHunks(ops)
+	if len(hunks) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", fromLabel, toLabel)
+	for _, h := range hunks {
+		h.write(&b)
+	}
+	return b.String()
+}
+
+// splitLines splits s into lines that keep their trailing newline, so a final
+// line without one can be flagged in the output.
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// editScript returns the shortest line edit script turning a into b.
+func editScript(a, b []string) []diffOp {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	return ops
+}
+
+// hunk is a run of changes plus surrounding context, starting at the given
+// zero-based line offsets into the old and new files.
+type hunk struct {
+	oldStart, newStart int
+	ops                []diffOp
+}
+
+// groupHunks splits an edit script into hunks, merging changes whose context
+// windows touch.
+func groupHunks(ops []diffOp) []hunk {
+	var hunks []hunk
+	oldLine, newLine := 0, 0
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			oldLine++
+			newLine++
+			i++
+			continue
+		}
+		start := max(i-diffContext, 0)
+		h := hunk{oldStart: oldLine - (i - start), newStart: newLine - (i - start)}
+		end := hunkEnd(ops, i)
+		for _, op := range ops[i:end] {
+			if op.kind != '+' {
+				oldLine++
+			}
+			if op.kind != '-' {
+				newLine++
+			}
+		}
+		h.ops = ops[start:end]
+		hunks = append(hunks, h)
+		i = end
+	}
+	return hunks
+}
+
+// hunkEnd returns the index just past the hunk that begins with the change at i,
+// including trailing context.
+func hunkEnd(ops []diffOp, i int) int {
+	unchanged := 0
+	for ; i < len(ops); i++ {
+		if ops[i].kind != ' ' {
+			unchanged = 0
+			continue
+		}
+		unchanged++
+		if unchanged > 2*diffContext {
+			return i - unchanged + 1 + diffContext
+		}
+	}
+	return len(ops) - max(unchanged-diffContext, 0)
+}
+
+func (h hunk) write(b *strings.Builder) {
+	oldCount, newCount := 0, 0
+	for _, op := range h.ops {
+		if op.kind != '+' {
+			oldCount++
+		}
+		if op.kind != '-' {
+			newCount++
+		}
+	}
+	fmt.Fprintf(b, "@@ -%s +%s @@\n", hunkRange(h.oldStart, oldCount), hunkRange(h.newStart, newCount))
+	for _, op := range h.ops {
+		b.WriteByte(op.kind)
+		b.WriteString(op.line)
+		if !strings.HasSuffix(op.line, "\n") {
+			b.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats one side of a hunk header. An empty range names the line
+// before it, so a file created from nothing reads "-0,0".
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
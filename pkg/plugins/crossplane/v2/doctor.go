@@ -0,0 +1,493 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"golang.org/x/mod/modfile"
+	"sigs.k8s.io/kubebuilder/v4/pkg/config"
+	"sigs.k8s.io/kubebuilder/v4/pkg/config/store/yaml"
+	"sigs.k8s.io/kubebuilder/v4/pkg/machinery"
+
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/core"
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/templates/engine"
+	"github.com/cychiang/xp-provider-gen/pkg/versions"
+)
+
+// minMakeVersion is the oldest make the build submodule's makelib runs under:
+// it sets .RECIPEPREFIX, which GNU Make added in 3.82.
+const minMakeVersion = "3.82"
+
+// NewDoctorCommand returns the `doctor` command, which diagnoses a provider and
+// the tools it builds with, and with --fix repairs what it can.
+func NewDoctorCommand() *cobra.Command {
+	var fix bool
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose a provider project and its toolchain",
+		Long: `Check a generated provider and the tools it builds with, reporting each problem
+with what to do about it:
+
+- git, go and make are installed: go no older than the go directive the generator
+  writes, make GNU Make ` + minMakeVersion + ` or later
+- the build submodule is present and initialized
+- PROJECT is valid, go.mod's module path is PROJECT's repo, and its go directive
+  is no older than the generator's
+- tool-owned files still carry the generated header, so 'update' refreshes them
+- the seam names generated code calls are still defined
+
+With --fix, the problems the generator can repair are repaired: the submodule is
+added or initialized, the module moved to PROJECT's repo (imports included), the
+go directive raised and the header restored. The repairs are left uncommitted for
+review. doctor exits non-zero while any problem remains.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			// Problems are a result, not a usage mistake.
+			cmd.SilenceUsage = true
+			return runDoctor(newDoctorEnv(), fix, cmd.OutOrStdout())
+		},
+	}
+	cmd.Flags().BoolVar(&fix, "fix", false, "repair the problems that can be repaired automatically")
+	return cmd
+}
+
+// doctorEnv is what the checks inspect: the project directory and the tools on
+// PATH. Tests substitute both.
+type doctorEnv struct {
+	disk afero.Fs
+	// run returns a command's output, failing when the command is missing.
+	run func(name string, args ...string) (string, error)
+
+	// cfg is the project, set by the PROJECT check once it is found valid.
+	cfg config.Config
+}
+
+func newDoctorEnv() *doctorEnv {
+	return &doctorEnv{
+		disk: afero.NewOsFs(),
+		run: func(name string, args ...string) (string, error) {
+			return core.NewCommandRunner("").RunWithOutput(context.Background(), name, args...)
+		},
+	}
+}
+
+// finding is a problem a check found. fix repairs it, and is nil when only the
+// user can; hint then says how.
+type finding struct {
+	problem string
+	hint    string
+	fix     func() error
+}
+
+// doctorCheck is one diagnosis; run returns no findings when all is well.
+type doctorCheck struct {
+	name         string
+	needsProject bool
+	run          func(env *doctorEnv) []finding
+}
+
+// doctorChecks run in order. The PROJECT check loads the project for the
+// checks after it that need one.
+var doctorChecks = []doctorCheck{
+	{name: "toolchain", run: checkToolchain},
+	{name: "build submodule", run: checkBuildSubmodule},
+	{name: "PROJECT", run: checkProject},
+	{name: "go.mod", needsProject: true, run: checkGoMod},
+	{name: "generated headers", needsProject: true, run: checkHeaders},
+	{name: "seam names", needsProject: true, run: checkSeams},
+}
+
+func runDoctor(env *doctorEnv, fix bool, out io.Writer) error {
+	remaining, fixed := 0, 0
+	for _, check := range doctorChecks {
+		if check.needsProject && env.cfg == nil {
+			fmt.Fprintf(out, "skip  %s (needs a valid PROJECT)\n", check.name)
+			continue
+		}
+		findings := check.run(env)
+		if len(findings) == 0 {
+			fmt.Fprintf(out, "ok    %s\n", check.name)
+			continue
+		}
+		for _, f := range findings {
+			fmt.Fprintf(out, "FAIL  %s: %s\n", check.name, f.problem)
+			switch {
+			case f.fix != nil && fix:
+				if err := f.fix(); err != nil {
+					fmt.Fprintf(out, "      repair failed: %v\n", err)
+					remaining++
+					continue
+				}
+				fmt.Fprintln(out, "      repaired")
+				fixed++
+			case f.fix != nil:
+				fmt.Fprintln(out, "      run 'xp-provider-gen doctor --fix' to repair it")
+				remaining++
+			default:
+				fmt.Fprintf(out, "      %s\n", f.hint)
+				remaining++
+			}
+		}
+	}
+
+	if fixed > 0 {
+		fmt.Fprintf(out, "\nRepaired %d problem(s); review them with 'git diff' and commit.\n", fixed)
+	}
+	if remaining > 0 {
+		return fmt.Errorf("doctor found %d problem(s)", remaining)
+	}
+	if fixed == 0 {
+		fmt.Fprintln(out, "\nNo problems found.")
+	}
+	return nil
+}
+
+// tool is an executable a provider builds with, and the oldest version of it
+// that works; "" when any will do.
+type tool struct {
+	name    string
+	version []string
+	min     string
+}
+
+// versionRe finds the version number in a tool's version output.
+var versionRe = regexp.MustCompile(`\d+\.\d+(\.\d+)?`)
+
+func checkToolchain(env *doctorEnv) []finding {
+	tools := []tool{
+		// The git commands the generator runs are long-standing; any git will do.
+		{name: "git", version: []string{"--version"}},
+		{name: "go", version: []string{"env", "GOVERSION"}, min: versions.GoVersion},
+		{name: "make", version: []string{"--version"}, min: minMakeVersion},
+	}
+	var findings []finding
+	for _, t := range tools {
+		out, err := env.run(t.name, t.version...)
+		if err != nil {
+			findings = append(findings, finding{
+				problem: fmt.Sprintf("%s is not installed or not on PATH", t.name),
+				hint:    fmt.Sprintf("install %s", describeTool(t)),
+			})
+			continue
+		}
+		if t.min == "" {
+			continue
+		}
+		found := versionRe.FindString(out)
+		if found == "" || compareVersions(found, t.min) < 0 {
+			findings = append(findings, finding{
+				problem: fmt.Sprintf("%s is version %q, older than %s", t.name, strings.TrimSpace(firstLine(out)), t.min),
+				hint:    fmt.Sprintf("install %s", describeTool(t)),
+			})
+		}
+	}
+	return findings
+}
+
+func describeTool(t tool) string {
+	if t.min == "" {
+		return t.name
+	}
+	return fmt.Sprintf("%s %s or later", t.name, t.min)
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+// compareVersions compares dotted version numbers numerically, a missing part
+// counting as 0. It returns -1, 0 or +1.
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
+
+// buildSubmoduleRe matches the build submodule's entry in .gitmodules.
+var buildSubmoduleRe = regexp.MustCompile(`(?m)^\s*path\s*=\s*build\s*$`)
+
+func checkBuildSubmodule(env *doctorEnv) []finding {
+	modules, _ := afero.ReadFile(env.disk, ".gitmodules")
+	if !buildSubmoduleRe.Match(modules) {
+		url, ref := buildSubmoduleSource(env)
+		return []finding{{
+			problem: "the build submodule is missing, so make has no build system",
+			fix: func() error {
+				if _, err := env.run("git", "submodule", "add", url, "build"); err != nil {
+					return err
+				}
+				if ref != "" {
+					if _, err := env.run("git", "-C", "build", "checkout", "--quiet", ref); err != nil {
+						return err
+					}
+					if _, err := env.run("git", "add", "build"); err != nil {
+						return err
+					}
+				}
+				_, err := env.run("git", "submodule", "update", "--init", "--recursive")
+				return err
+			},
+		}}
+	}
+	if exists, _ := afero.Exists(env.disk, "build/makelib"); !exists {
+		return []finding{{
+			problem: "the build submodule is not initialized",
+			fix: func() error {
+				_, err := env.run("git", "submodule", "update", "--init", "--recursive")
+				return err
+			},
+		}}
+	}
+	return nil
+}
+
+// buildSubmoduleSource returns the URL and ref of the build submodule the
+// project was created with. This check runs before PROJECT's, so it reads
+// PROJECT itself, and falls back to the configured defaults when it cannot.
+func buildSubmoduleSource(env *doctorEnv) (url, ref string) {
+	c, err := loadPluginConfig(nil)
+	if err != nil {
+		c = NewPluginConfig()
+	}
+	st := yaml.New(machinery.Filesystem{FS: env.disk})
+	if st.Load() == nil {
+		if settings, err := core.LoadProjectSettings(st.Config()); err == nil {
+			c.ApplyProject(settings)
+		}
+	}
+	return c.Git.BuildSubmoduleURL, c.Git.BuildSubmoduleRef
+}
+
+func checkProject(env *doctorEnv) []finding {
+	st := yaml.New(machinery.Filesystem{FS: env.disk})
+	if err := st.Load(); err != nil {
+		return []finding{{
+			problem: fmt.Sprintf("cannot load PROJECT: %v", err),
+			hint:    "run doctor in the provider's root directory",
+		}}
+	}
+	if err := validateProject(st.Config()); err != nil {
+		return []finding{{problem: err.Error(), hint: "correct PROJECT by hand"}}
+	}
+	env.cfg = st.Config()
+	return nil
+}
+
+func checkGoMod(env *doctorEnv) []finding {
+	b, err := afero.ReadFile(env.disk, "go.mod")
+	if err != nil {
+		return []finding{{problem: fmt.Sprintf("cannot read go.mod: %v", err), hint: "restore go.mod from git"}}
+	}
+	f, err := modfile.Parse("go.mod", b, nil)
+	if err != nil {
+		return []finding{{problem: err.Error(), hint: "correct the syntax error in go.mod"}}
+	}
+
+	var findings []finding
+	repo := env.cfg.GetRepository()
+	if module := f.Module.Mod.Path; module != repo {
+		findings = append(findings, finding{
+			problem: fmt.Sprintf("go.mod declares module %s, but PROJECT's repo is %s", module, repo),
+			fix: func() error {
+				_, err := rewriteModulePath(env.disk, module, repo)
+				return err
+			},
+		})
+	}
+	// A go directive newer than the generator's is fine: go get raises it when
+	// a dependency needs a newer Go.
+	if f.Go == nil || compareVersions(f.Go.Version, versions.GoVersion) < 0 {
+		current := "none"
+		if f.Go != nil {
+			current = f.Go.Version
+		}
+		findings = append(findings, finding{
+			problem: fmt.Sprintf("go.mod's go directive is %s, older than the generator's %s", current, versions.GoVersion),
+			fix: func() error {
+				return rewriteFile(env.disk, "go.mod", func(b []byte) ([]byte, error) {
+					f, err := modfile.Parse("go.mod", b, nil)
+					if err != nil {
+						return nil, err
+					}
+					if err := f.AddGoStmt(versions.GoVersion); err != nil {
+						return nil, err
+					}
+					return f.Format()
+				})
+			},
+		})
+	}
+	return findings
+}
+
+// checkHeaders finds the files the templates own that have lost the generated
+// header on disk. A missing file is not a problem: update seeds it.
+func checkHeaders(env *doctorEnv) []finding {
+	mem := afero.NewMemMapFs()
+	if err := renderToMemFS(env.cfg, machinery.Filesystem{FS: mem}); err != nil {
+		return []finding{{problem: fmt.Sprintf("cannot render the templates: %v", err), hint: "correct PROJECT by hand"}}
+	}
+	var findings []finding
+	err := afero.Walk(mem, ".", func(srcPath string, info fs.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rendered, err := afero.ReadFile(mem, srcPath)
+		if err != nil || !core.IsToolOwned(rendered) {
+			return err
+		}
+		rel := strings.TrimPrefix(filepath.ToSlash(srcPath), "/")
+		existing, err := afero.ReadFile(env.disk, rel)
+		if err != nil || core.IsToolOwned(existing) {
+			return nil
+		}
+		findings = append(findings, finding{
+			problem: fmt.Sprintf("%s has lost its generated header, so update no longer refreshes it", rel),
+			fix: func() error {
+				_, _, err := adoptFile(mem, env.disk, srcPath)
+				return err
+			},
+		})
+		return nil
+	})
+	if err != nil {
+		return []finding{{problem: fmt.Sprintf("cannot compare the generated files: %v", err), hint: "run doctor again"}}
+	}
+	return findings
+}
+
+// checkSeams finds the seam names that are no longer defined. A seam may move
+// to another file of its package, so the whole package is searched.
+func checkSeams(env *doctorEnv) []finding {
+	resources, err := env.cfg.GetResources()
+	if err != nil {
+		return []finding{{problem: fmt.Sprintf("reading project resources: %v", err), hint: "correct PROJECT by hand"}}
+	}
+	var kinds []string
+	seen := map[string]bool{}
+	for _, res := range engine.ManagedResources(resources) {
+		if kind := strings.ToLower(res.Kind); !seen[kind] {
+			seen[kind] = true
+			kinds = append(kinds, kind)
+		}
+	}
+
+	var findings []finding
+	for _, seam := range engine.Seams {
+		files := []string{seam.File}
+		if strings.Contains(seam.File, "<kind>") {
+			files = nil
+			for _, kind := range kinds {
+				files = append(files, strings.ReplaceAll(seam.File, "<kind>", kind))
+			}
+		}
+		for _, file := range files {
+			dir := path.Dir(file)
+			defined, err := packageDecls(env.disk, dir)
+			if err != nil {
+				findings = append(findings, finding{problem: err.Error(), hint: "restore the package from git"})
+				continue
+			}
+			for _, name := range seam.Names {
+				if !defined[name] {
+					findings = append(findings, finding{
+						problem: fmt.Sprintf("%s is not defined in %s", name, dir),
+						hint:    fmt.Sprintf("generated code calls it by name; define it again (the scaffold had it in %s)", file),
+					})
+				}
+			}
+		}
+	}
+	return findings
+}
+
+// packageDecls returns the names declared at the top level of the Go package in
+// dir, leaving out methods and test files.
+func packageDecls(disk afero.Fs, dir string) (map[string]bool, error) {
+	entries, err := afero.ReadDir(disk, dir)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%s is missing", dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	decls := map[string]bool{}
+	fset := token.NewFileSet()
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		src, err := afero.ReadFile(disk, path.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		file, err := parser.ParseFile(fset, path.Join(dir, name), src, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range file.Decls {
+			switch d := d.(type) {
+			case *ast.FuncDecl:
+				if d.Recv == nil {
+					decls[d.Name.Name] = true
+				}
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					switch s := spec.(type) {
+					case *ast.TypeSpec:
+						decls[s.Name.Name] = true
+					case *ast.ValueSpec:
+						for _, n := range s.Names {
+							decls[n.Name] = true
+						}
+					}
+				}
+			}
+		}
+	}
+	return decls, nil
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"sigs.k8s.io/kubebuilder/v4/pkg/machinery"
+
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/core"
+	"github.com/cychiang/xp-provider-gen/pkg/versions"
+)
+
+// healthyProvider returns a doctor environment for a freshly rendered provider
+// whose tools are all present and recent enough. Commands it runs are recorded
+// in ran.
+func healthyProvider(t *testing.T, ran *[]string) *doctorEnv {
+	t.Helper()
+	cfg := webhookProject(t, nil)
+	disk := afero.NewMemMapFs()
+	if err := renderToMemFS(cfg, machinery.Filesystem{FS: disk}); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	project, err := cfg.MarshalYAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for path, content := range map[string]string{
+		"PROJECT":                 string(project),
+		"go.mod":                  "module github.com/example/provider-test\n\ngo " + versions.GoVersion + "\n",
+		".gitmodules":             "[submodule \"build\"]\n\tpath = build\n\turl = https://github.com/crossplane/build\n",
+		"build/makelib/common.mk": "",
+	} {
+		if err := afero.WriteFile(disk, path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &doctorEnv{
+		disk: disk,
+		run: func(name string, args ...string) (string, error) {
+			*ran = append(*ran, strings.Join(append([]string{name}, args...), " "))
+			switch name {
+			case "go":
+				return "go" + versions.GoVersion + "\n", nil
+			case "make":
+				return "GNU Make 4.3\nBuilt for x86_64-pc-linux-gnu\n", nil
+			}
+			return "git version 2.43.0\n", nil
+		},
+	}
+}
+
+func TestRunDoctorHealthy(t *testing.T) {
+	var ran []string
+	var out bytes.Buffer
+	if err := runDoctor(healthyProvider(t, &ran), false, &out); err != nil {
+		t.Fatalf("runDoctor() = %v\n%s", err, out.String())
+	}
+	if strings.Contains(out.String(), "FAIL") {
+		t.Errorf("a healthy provider has problems:\n%s", out.String())
+	}
+}
+
+func TestRunDoctorFix(t *testing.T) {
+	var ran []string
+	env := healthyProvider(t, &ran)
+	_ = afero.WriteFile(env.disk, "go.mod", []byte("module github.com/old/provider-test\n\ngo 1.22\n"), 0o644)
+	wiring := "internal/controller/bucket/wiring.go"
+	b, _ := afero.ReadFile(env.disk, wiring)
+	_ = afero.WriteFile(env.disk, wiring, bytes.Replace(b, []byte(core.GeneratedHeader), nil, 1), 0o644)
+
+	var out bytes.Buffer
+	if err := runDoctor(env, false, &out); err == nil || !strings.Contains(err.Error(), "3 problem(s)") {
+		t.Fatalf("runDoctor() = %v, want 3 problems\n%s", err, out.String())
+	}
+
+	out.Reset()
+	if err := runDoctor(env, true, &out); err != nil {
+		t.Fatalf("runDoctor(fix) = %v\n%s", err, out.String())
+	}
+	mod, _ := afero.ReadFile(env.disk, "go.mod")
+	if want := "module github.com/example/provider-test\n\ngo " + versions.GoVersion + "\n"; string(mod) != want {
+		t.Errorf("go.mod = %q, want %q", mod, want)
+	}
+	if b, _ := afero.ReadFile(env.disk, wiring); !core.IsToolOwned(b) {
+		t.Errorf("%s did not get its header back", wiring)
+	}
+
+	out.Reset()
+	if err := runDoctor(env, false, &out); err != nil {
+		t.Errorf("problems remain after --fix: %v\n%s", err, out.String())
+	}
+}
+
+func TestCheckToolchain(t *testing.T) {
+	cases := map[string]struct {
+		outputs map[string]string
+		want    []string
+	}{
+		"Current": {
+			outputs: map[string]string{"git": "git version 2.43.0", "go": "go" + versions.GoVersion, "make": "GNU Make 4.3"},
+		},
+		"OldMakeAndMissingGit": {
+			outputs: map[string]string{"go": "go" + versions.GoVersion, "make": "GNU Make 3.81"},
+			want:    []string{"git is not installed", "make is version \"GNU Make 3.81\""},
+		},
+		"OldGo": {
+			outputs: map[string]string{"git": "git version 2.43.0", "go": "go1.21.5", "make": "GNU Make 4.3"},
+			want:    []string{"go is version \"go1.21.5\""},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			env := &doctorEnv{run: func(name string, _ ...string) (string, error) {
+				out, ok := tc.outputs[name]
+				if !ok {
+					return "", errors.New("not found")
+				}
+				return out, nil
+			}}
+			findings := checkToolchain(env)
+			if len(findings) != len(tc.want) {
+				t.Fatalf("findings = %+v, want %d", findings, len(tc.want))
+			}
+			for i, want := range tc.want {
+				if !strings.Contains(findings[i].problem, want) {
+					t.Errorf("finding %d = %q, want it to contain %q", i, findings[i].problem, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckBuildSubmodule(t *testing.T) {
+	var ran []string
+	env := healthyProvider(t, &ran)
+	_ = env.disk.RemoveAll("build")
+
+	findings := checkBuildSubmodule(env)
+	if len(findings) != 1 || !strings.Contains(findings[0].problem, "not initialized") {
+		t.Fatalf("findings = %+v, want the submodule reported uninitialized", findings)
+	}
+	if err := findings[0].fix(); err != nil {
+		t.Fatal(err)
+	}
+	if len(ran) != 1 || ran[0] != "git submodule update --init --recursive" {
+		t.Errorf("fix ran %q", ran)
+	}
+
+	_ = env.disk.Remove(".gitmodules")
+	findings = checkBuildSubmodule(env)
+	if len(findings) != 1 || !strings.Contains(findings[0].problem, "missing") {
+		t.Fatalf("findings = %+v, want the submodule reported missing", findings)
+	}
+}
+
+func TestCheckSeams(t *testing.T) {
+	var ran []string
+	env := healthyProvider(t, &ran)
+	env.cfg = webhookProject(t, nil)
+
+	external := "internal/controller/bucket/external.go"
+	b, _ := afero.ReadFile(env.disk, external)
+	_ = afero.WriteFile(env.disk, external,
+		bytes.Replace(b, []byte("func NewExternal("), []byte("func newExternal("), 1), 0o644)
+	_ = env.disk.Remove("internal/provider/options.go")
+	_ = afero.WriteFile(env.disk, "internal/provider/flags.go", []byte(`package provider
+
+func Flags() {}
+
+var Configure = func() error { return nil }
+`), 0o644)
+
+	findings := checkSeams(env)
+	if len(findings) != 1 || findings[0].problem != "NewExternal is not defined in internal/controller/bucket" {
+		t.Errorf("findings = %+v, want only NewExternal missing", findings)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{"1.26.0", "1.26", 0},
+		{"1.9", "1.26.0", -1},
+		{"4.3", "3.82", 1},
+		{"3.81", "3.82", -1},
+	} {
+		if got := compareVersions(tc.a, tc.b); got != tc.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,493 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/pflag"
+	"golang.org/x/mod/modfile"
+	"sigs.k8s.io/kubebuilder/v4/pkg/config"
+	cfgv3 "sigs.k8s.io/kubebuilder/v4/pkg/config/v3"
+	"sigs.k8s.io/kubebuilder/v4/pkg/machinery"
+	"sigs.k8s.io/kubebuilder/v4/pkg/plugin"
+
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/automation"
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/core"
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/validation"
+)
+
+var _ plugin.EditSubcommand = &editSubcommand{}
+
+// yamlRoots are the directories whose YAML names the provider's API groups:
+// the examples and the tests. Generated manifests are regenerated instead.
+var yamlRoots = []string{"examples", "test"}
+
+type editSubcommand struct {
+	config config.Config
+
+	domain string
+	repo   string
+
+	// The values before the edit, which the rewrite looks for.
+	oldDomain string
+	oldRepo   string
+
+	pluginConfig *PluginConfig
+}
+
+func (p *editSubcommand) UpdateMetadata(cliMeta plugin.CLIMetadata, subcmdMeta *plugin.SubcommandMetadata) {
+	p.ensureConfig()
+
+	subcmdMeta.Description = `Change an initialized provider's Go module path or API domain.
+
+This rewrites:
+- every Go import of the module, in your files and generated ones alike, and
+  the module line in go.mod
+- the API groups in the YAML under examples/ and test/
+- PROJECT, after which the tool-owned files are re-rendered
+
+Seed-once files that still mention the old values, such as the Makefile and
+package/crossplane.yaml, are listed for you to update. The working tree must be
+clean, so the change lands as one reviewable commit.`
+
+	subcmdMeta.Examples = fmt.Sprintf(`  # Move the provider to another GitHub organization
+  %[1]s edit --repo=github.com/acme/provider-aws
+
+  # Serve the API groups under a new domain
+  %[1]s edit --domain=acme.io`, cliMeta.CommandName)
+}
+
+func (p *editSubcommand) BindFlags(fs *pflag.FlagSet) {
+	p.ensureConfig()
+
+	fs.StringVar(&p.domain, "domain", "", "new domain for API groups")
+	fs.StringVar(&p.repo, "repo", "", "new name of the go module (e.g., github.com/user/repo)")
+}
+
+// InjectConfig validates the new values and loads the settings the project was
+// created with, which the post-edit commit runs with. Scaffold applies the values
+// to the project model, which kubebuilder saves to PROJECT once Scaffold succeeds.
+func (p *editSubcommand) InjectConfig(c config.Config) error {
+	p.config = c
+	p.oldDomain = c.GetDomain()
+	p.oldRepo = c.GetRepository()
+	if p.domain == "" {
+		p.domain = p.oldDomain
+	}
+	if p.repo == "" {
+		p.repo = p.oldRepo
+	}
+	if p.domain == p.oldDomain && p.repo == p.oldRepo {
+		return validation.EditError("flag check",
+			fmt.Errorf("pass --domain, --repo or both with a value the project does not already have"))
+	}
+
+	validator := validation.NewValidator()
+	if err := validator.ValidateDomain(p.domain); err != nil {
+		return validation.EditError("domain validation", err)
+	}
+	if err := validator.ValidateRepository(p.repo); err != nil {
+		return validation.EditError("repository validation", err)
+	}
+	pluginConfig, err := loadPluginConfig(c)
+	if err != nil {
+		return validation.EditError("generator settings", err)
+	}
+	p.pluginConfig = pluginConfig
+	return nil
+}
+
+func (p *editSubcommand) PreScaffold(machinery.Filesystem) error {
+	if err := requireCleanTree(context.Background()); err != nil {
+		return validation.EditError("working tree check", err)
+	}
+	return nil
+}
+
+// Scaffold moves the project model, rewrites what the user owns, then
+// re-renders what the tool owns from the edited project.
+func (p *editSubcommand) Scaffold(fs machinery.Filesystem) error {
+	fmt.Printf("Changing provider %s\n", p.change())
+
+	if err := p.move(); err != nil {
+		return err
+	}
+	// The rewrites reach tool-owned files too, so they are locked with the
+	// re-rendered ones; `update` would otherwise take them for hand edits.
+	disk := trackWrites(fs.FS)
+	if p.repo != p.oldRepo {
+		rewritten, err := rewriteModulePath(disk, p.oldRepo, p.repo)
+		if err != nil {
+			return validation.EditError("import rewrite", err)
+		}
+		fmt.Printf("Rewrote imports in %d files\n", len(rewritten))
+	}
+	if p.domain != p.oldDomain {
+		groups, err := projectGroups(p.config)
+		if err != nil {
+			return validation.EditError("group rewrite", err)
+		}
+		rewritten, err := rewriteGroups(disk, newGroupRewrite(groups, p.oldDomain, p.domain))
+		if err != nil {
+			return validation.EditError("group rewrite", err)
+		}
+		fmt.Printf("Rewrote API groups in %d files\n", len(rewritten))
+	}
+
+	mem := afero.NewMemMapFs()
+	if err := renderToMemFS(p.config, machinery.Filesystem{FS: mem}); err != nil {
+		return validation.EditError("scaffolding", err)
+	}
+	result, err := reconcile(mem, disk)
+	if err != nil {
+		return validation.EditError("scaffolding", err)
+	}
+	if err := disk.saveLock(); err != nil {
+		return validation.EditError("scaffolding", err)
+	}
+	result.print()
+
+	stale, err := mentioning(fs.FS, p.staleValues())
+	if err != nil {
+		return validation.EditError("scaffolding", err)
+	}
+	if len(stale) > 0 {
+		fmt.Println("These files are yours and still mention the old values; update them by hand:")
+		for _, f := range stale {
+			fmt.Printf("  %s\n", f)
+		}
+	}
+	return nil
+}
+
+func (p *editSubcommand) PostScaffold() error {
+	p.ensureConfig()
+
+	pipeline := automation.NewEditPipeline(p.pluginConfig, p.change())
+	fmt.Println("Running post-edit automation...")
+	if err := pipeline.Run(); err != nil {
+		return validation.EditError("post-edit automation", err)
+	}
+
+	fmt.Printf("Provider %s changed successfully!\n", p.change())
+	return nil
+}
+
+// move applies the new values to the project model.
+func (p *editSubcommand) move() error {
+	if err := moveProject(p.config, p.oldRepo, p.repo, p.oldDomain, p.domain); err != nil {
+		return validation.EditError("configuration", err)
+	}
+	if err := validateProject(p.config); err != nil {
+		return validation.EditError("configuration", err)
+	}
+	return nil
+}
+
+// change describes the edit, for progress output and the commit message.
+func (p *editSubcommand) change() string {
+	var parts []string
+	if p.repo != p.oldRepo {
+		parts = append(parts, "module path to "+p.repo)
+	}
+	if p.domain != p.oldDomain {
+		parts = append(parts, "domain to "+p.domain)
+	}
+	return strings.Join(parts, " and ")
+}
+
+// staleValues are the old values that should no longer appear in the tree.
+func (p *editSubcommand) staleValues() []string {
+	var values []string
+	if p.repo != p.oldRepo {
+		values = append(values, p.oldRepo)
+	}
+	if p.domain != p.oldDomain {
+		values = append(values, p.oldDomain)
+	}
+	return values
+}
+
+func (p *editSubcommand) ensureConfig() {
+	if p.pluginConfig == nil {
+		p.pluginConfig = NewPluginConfig()
+	}
+}
+
+// moveProject sets the project's repository and domain, and moves its resources
+// along: those under the old module get the new package path, those in the old
+// domain the new domain. Kubebuilder's config interface cannot change a
+// resource's domain (it is part of the GVK that identifies it), so this edits
+// the v3 model directly — the only project version the plugin supports.
+func moveProject(c config.Config, oldRepo, repo, oldDomain, domain string) error {
+	cfg, ok := c.(*cfgv3.Cfg)
+	if !ok {
+		return fmt.Errorf("unsupported project version %s", c.GetVersion())
+	}
+	if err := cfg.SetRepository(repo); err != nil {
+		return err
+	}
+	if err := cfg.SetDomain(domain); err != nil {
+		return err
+	}
+	for i := range cfg.Resources {
+		res := &cfg.Resources[i]
+		if rest, ok := strings.CutPrefix(res.Path, oldRepo+"/"); ok {
+			res.Path = repo + "/" + rest
+		}
+		if res.Domain == oldDomain {
+			res.Domain = domain
+		}
+	}
+	return nil
+}
+
+// projectGroups returns the project's API groups, without the domain.
+func projectGroups(cfg config.Config) ([]string, error) {
+	resources, err := cfg.GetResources()
+	if err != nil {
+		return nil, fmt.Errorf("reading project resources: %w", err)
+	}
+	seen := map[string]bool{}
+	var groups []string
+	for _, res := range resources {
+		if !seen[res.Group] {
+			seen[res.Group] = true
+			groups = append(groups, res.Group)
+		}
+	}
+	return groups, nil
+}
+
+// rewriteModulePath moves the module at disk's root from one path to another:
+// the module line in go.mod and every import of one of its packages. It returns
+// the files it changed.
+func rewriteModulePath(disk afero.Fs, from, to string) ([]string, error) {
+	var changed []string
+	if err := rewriteFile(disk, "go.mod", func(b []byte) ([]byte, error) {
+		f, err := modfile.Parse("go.mod", b, nil)
+		if err != nil {
+			return nil, err
+		}
+		if err := f.AddModuleStmt(to); err != nil {
+			return nil, err
+		}
+		return f.Format()
+	}); err != nil {
+		return nil, err
+	}
+
+	err := walkModule(disk, func(rel string) error {
+		if filepath.Ext(rel) != ".go" {
+			return nil
+		}
+		b, err := afero.ReadFile(disk, rel)
+		if err != nil {
+			return err
+		}
+		edited, err := rewriteImports(b, from, to)
+		if err != nil {
+			return fmt.Errorf("%s: %w", rel, err)
+		}
+		if bytes.Equal(edited, b) {
+			return nil
+		}
+		changed = append(changed, rel)
+		return afero.WriteFile(disk, rel, edited, 0o644)
+	})
+	return changed, err
+}
+
+// rewriteImports points a Go file's imports of the module from, and of the
+// packages under it, at the same packages under to. The last element of each
+// path stays the same, so no identifier changes with it. A file that imports
+// nothing from the module is returned unchanged.
+func rewriteImports(src []byte, from, to string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	changed := false
+	for _, spec := range file.Imports {
+		p, _ := strconv.Unquote(spec.Path.Value)
+		if p != from && !strings.HasPrefix(p, from+"/") {
+			continue
+		}
+		spec.Path.Value = strconv.Quote(to + strings.TrimPrefix(p, from))
+		changed = true
+	}
+	if !changed {
+		return src, nil
+	}
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// rewriteGroups moves the provider's API groups in the YAML under yamlRoots
+// as r says, returning the files it changed.
+func rewriteGroups(disk afero.Fs, r *groupRewrite) ([]string, error) {
+	var changed []string
+	for _, root := range yamlRoots {
+		if exists, _ := afero.DirExists(disk, root); !exists {
+			continue
+		}
+		err := afero.Walk(disk, root, func(rel string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			if ext := filepath.Ext(rel); ext != ".yaml" && ext != ".yml" {
+				return nil
+			}
+			b, err := afero.ReadFile(disk, rel)
+			if err != nil {
+				return err
+			}
+			edited := r.replace(b)
+			if bytes.Equal(edited, b) {
+				return nil
+			}
+			changed = append(changed, rel)
+			return afero.WriteFile(disk, rel, edited, 0o644)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return changed, nil
+}
+
+// dnsName matches the longest run of characters a lowercase DNS name is made of.
+var dnsName = regexp.MustCompile(`[-.a-z0-9]+`)
+
+// groupRewrite moves the provider's API groups from one domain to another.
+type groupRewrite struct {
+	groups   []string
+	from, to string
+
+	// apiVersion matches an apiVersion in the bare from domain.
+	apiVersion *regexp.Regexp
+}
+
+func newGroupRewrite(groups []string, from, to string) *groupRewrite {
+	return &groupRewrite{
+		groups:     groups,
+		from:       from,
+		to:         to,
+		apiVersion: regexp.MustCompile(`(?m)^(\s*(?:- )?apiVersion:\s*["']?)` + regexp.QuoteMeta(from) + `/`),
+	}
+}
+
+// replace replaces the API groups the provider owns: each "<group>.<from>"
+// wherever it ends a DNS name (an apiVersion, a CRD name), and the bare domain —
+// the ProviderConfig group — only in an apiVersion, since elsewhere it may just
+// as well be a host name.
+func (r *groupRewrite) replace(src []byte) []byte {
+	src = dnsName.ReplaceAllFunc(src, func(name []byte) []byte {
+		for _, g := range r.groups {
+			owned := g + "." + r.from
+			if head, ok := bytes.CutSuffix(name, []byte(owned)); ok &&
+				(len(head) == 0 || bytes.HasSuffix(head, []byte("."))) {
+				return append(head, g+"."+r.to...)
+			}
+		}
+		return name
+	})
+	return r.apiVersion.ReplaceAll(src, []byte("${1}"+r.to+"/"))
+}
+
+// mentioning returns the module's user-owned files that contain any of the
+// given values. PROJECT, tool-owned files and codegen output are left out: they
+// are rewritten, re-rendered or regenerated.
+func mentioning(disk afero.Fs, values []string) ([]string, error) {
+	var found []string
+	err := walkModule(disk, func(rel string) error {
+		if rel == "PROJECT" || isCodegenOutput(rel) {
+			return nil
+		}
+		b, err := afero.ReadFile(disk, rel)
+		if err != nil {
+			return err
+		}
+		if core.IsToolOwned(b) {
+			return nil
+		}
+		for _, v := range values {
+			if bytes.Contains(b, []byte(v)) {
+				found = append(found, rel)
+				return nil
+			}
+		}
+		return nil
+	})
+	sort.Strings(found)
+	return found, err
+}
+
+// isCodegenOutput reports whether rel is written by `make generate`.
+func isCodegenOutput(rel string) bool {
+	for _, dir := range []string{"package/crds/", "package/webhookconfigurations/"} {
+		if strings.HasPrefix(filepath.ToSlash(rel), dir) {
+			return true
+		}
+	}
+	return strings.HasPrefix(filepath.Base(rel), "zz_generated")
+}
+
+// walkModule calls fn for each file of the module at disk's root. Like the go
+// command, it skips directories starting with "." or "_" and vendor; it also
+// skips nested modules and git submodules, such as the build submodule, whose
+// files belong to someone else.
+func walkModule(disk afero.Fs, fn func(rel string) error) error {
+	return afero.Walk(disk, ".", func(rel string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return fn(rel)
+		}
+		if rel == "." {
+			return nil
+		}
+		base := info.Name()
+		if strings.HasPrefix(base, ".") || strings.HasPrefix(base, "_") || base == "vendor" {
+			return filepath.SkipDir
+		}
+		for _, marker := range []string{"go.mod", ".git"} {
+			if exists, _ := afero.Exists(disk, filepath.Join(rel, marker)); exists {
+				return filepath.SkipDir
+			}
+		}
+		return nil
+	})
+}
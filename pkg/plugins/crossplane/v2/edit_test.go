@@ -0,0 +1,232 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kubebuilder/v4/pkg/machinery"
+
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/core"
+)
+
+func TestEditInjectConfig(t *testing.T) {
+	cases := map[string]struct {
+		args     []string
+		wantErr  string
+		wantPath string
+		wantGVK  string
+	}{
+		"NoChange": {
+			args:    []string{"--domain=example.com"},
+			wantErr: "pass --domain, --repo or both",
+		},
+		"InvalidDomain": {
+			args:    []string{"--domain=not a domain"},
+			wantErr: "invalid domain",
+		},
+		"Repo": {
+			args:     []string{"--repo=github.com/acme/provider-test"},
+			wantPath: "github.com/acme/provider-test/apis/storage/v1alpha1",
+			wantGVK:  "storage.example.com",
+		},
+		"Domain": {
+			args:     []string{"--domain=acme.io"},
+			wantPath: "github.com/example/provider-test/apis/storage/v1alpha1",
+			wantGVK:  "storage.acme.io",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := &editSubcommand{}
+			fs := pflag.NewFlagSet("edit", pflag.ContinueOnError)
+			p.BindFlags(fs)
+			if err := fs.Parse(tc.args); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg := webhookProject(t, nil)
+			err := p.InjectConfig(cfg)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("InjectConfig() error = %v, want one containing %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			// PreScaffold may still refuse the edit, so nothing moves until
+			// Scaffold.
+			if cfg.GetRepository() != p.oldRepo || cfg.GetDomain() != p.oldDomain {
+				t.Errorf("InjectConfig() changed the project to %s, %s", cfg.GetRepository(), cfg.GetDomain())
+			}
+			if err := p.move(); err != nil {
+				t.Fatal(err)
+			}
+			resources, _ := cfg.GetResources()
+			if got := resources[0].Path; got != tc.wantPath {
+				t.Errorf("Path = %q, want %q", got, tc.wantPath)
+			}
+			if got := resources[0].QualifiedGroup(); got != tc.wantGVK {
+				t.Errorf("QualifiedGroup() = %q, want %q", got, tc.wantGVK)
+			}
+		})
+	}
+}
+
+func TestRewriteImports(t *testing.T) {
+	const src = `package bucket
+
+import (
+	"context"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/v2/apis/common/v1"
+
+	other "github.com/example/provider-test2/apis"
+	"github.com/example/provider-test/apis/storage/v1alpha1"
+	"github.com/example/provider-test/internal/provider"
+)
+`
+	got, err := rewriteImports([]byte(src), "github.com/example/provider-test", "github.com/acme/provider-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`"github.com/acme/provider-test/apis/storage/v1alpha1"`,
+		`"github.com/acme/provider-test/internal/provider"`,
+		`other "github.com/example/provider-test2/apis"`,
+		`xpv1 "github.com/crossplane/crossplane-runtime/v2/apis/common/v1"`,
+	} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("rewritten file is missing %s:\n%s", want, got)
+		}
+	}
+	if strings.Contains(string(got), "github.com/example/provider-test/") {
+		t.Errorf("rewritten file still imports the old module:\n%s", got)
+	}
+
+	const unrelated = "package bucket\n\nimport \"context\"\n"
+	if got, _ := rewriteImports([]byte(unrelated), "github.com/example/provider-test", "x"); string(got) != unrelated {
+		t.Errorf("a file without module imports changed:\n%s", got)
+	}
+}
+
+func TestReplaceGroups(t *testing.T) {
+	const src = `apiVersion: storage.example.com/v1alpha1
+kind: Bucket
+spec:
+  providerConfigRef:
+    kind: ProviderConfig
+---
+apiVersion: example.com/v1alpha1
+kind: ProviderConfig
+---
+apiVersion: other.example.com/v1
+kind: Unrelated
+spec:
+  endpoint: https://api.example.com/example.com
+  crd: buckets.storage.example.com
+`
+	got := string(newGroupRewrite([]string{"storage"}, "example.com", "acme.io").replace([]byte(src)))
+	for _, want := range []string{
+		"apiVersion: storage.acme.io/v1alpha1",
+		"apiVersion: acme.io/v1alpha1",
+		"apiVersion: other.example.com/v1",
+		"endpoint: https://api.example.com/example.com",
+		"crd: buckets.storage.acme.io",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("rewritten YAML is missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestEditScaffold(t *testing.T) {
+	cfg := webhookProject(t, nil)
+	disk := trackWrites(afero.NewMemMapFs())
+	if err := renderToMemFS(cfg, machinery.Filesystem{FS: disk}); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	// A tool-owned file the templates no longer render, so only the import
+	// rewrite touches it.
+	const orphan = "internal/controller/legacy/wiring.go"
+	_ = afero.WriteFile(disk, orphan, []byte(core.GeneratedHeader+
+		"\npackage legacy\n\nimport _ \"github.com/example/provider-test/internal/provider\"\n"), 0o644)
+	if err := disk.saveLock(); err != nil {
+		t.Fatal(err)
+	}
+	_ = afero.WriteFile(disk, "go.mod", []byte("module github.com/example/provider-test\n\ngo 1.24\n"), 0o644)
+
+	p := &editSubcommand{repo: "github.com/acme/provider-test", domain: "acme.io"}
+	if err := p.InjectConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Scaffold(machinery.Filesystem{FS: disk}); err != nil {
+		t.Fatal(err)
+	}
+
+	for path, want := range map[string]string{
+		"go.mod":                                     "module github.com/acme/provider-test\n",
+		"internal/controller/bucket/external.go":     `"github.com/acme/provider-test/apis/storage/v1alpha1"`,
+		"internal/controller/bucket/wiring.go":       `"github.com/acme/provider-test/internal/provider"`,
+		"apis/storage/v1alpha1/groupversion_info.go": `"storage.acme.io"`,
+		"examples/storage/bucket.yaml":               "apiVersion: storage.acme.io/v1alpha1",
+		"examples/provider/config.yaml":              "apiVersion: acme.io/v1alpha1",
+	} {
+		b, err := afero.ReadFile(disk, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(b), want) {
+			t.Errorf("%s is missing %q:\n%s", path, want, b)
+		}
+	}
+
+	stale, err := mentioning(disk, p.staleValues())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range stale {
+		if strings.HasSuffix(f, ".go") {
+			t.Errorf("%s still mentions the old module or domain", f)
+		}
+	}
+
+	// The lock follows the rewrites, so `update` sees no hand edits.
+	mem := afero.NewMemMapFs()
+	if err := renderToMemFS(cfg, machinery.Filesystem{FS: mem}); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	drifted, err := findDrift(mem, disk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range drifted {
+		t.Errorf("%s reported as edited after the edit", d.path)
+	}
+	lock, err := core.ReadLock(disk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := afero.ReadFile(disk, orphan); lock.Drifted(orphan, b) {
+		t.Errorf("%s reported as edited after the edit", orphan)
+	}
+}
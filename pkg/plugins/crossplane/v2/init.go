@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/pflag"
 	"sigs.k8s.io/kubebuilder/v4/pkg/config"
@@ -27,14 +28,24 @@ type initSubcommand struct {
 	gitName  string
 	gitEmail string
 
+	clusterProviderConfig bool
+
+	license         string
+	copyrightOwner  string
+	copyrightYear   int
+	boilerplateFile string
+
 	pluginConfig *PluginConfig
+	// configErr is why the user config could not be loaded in BindFlags,
+	// which cannot return it; InjectConfig does.
+	configErr error
 }
 
 func (p *initSubcommand) UpdateMetadata(cliMeta plugin.CLIMetadata, subcmdMeta *plugin.SubcommandMetadata) {
 	subcmdMeta.Description = `Initialize a new Crossplane provider project.
 
 This command scaffolds a complete Crossplane provider project with:
-- ProviderConfig APIs for authentication
+- ProviderConfig APIs for authentication (optionally a ClusterProviderConfig too)
 - Package metadata for Crossplane registry
 - Build system integration via git submodules
 - Controller scaffolding following Crossplane v2 patterns
@@ -51,8 +62,19 @@ This command scaffolds a complete Crossplane provider project with:
 
   # Initialize with specific git user configuration
   %s init --domain=example.com --repo=github.com/example/provider-aws \
-    --git-name="Crossplane Provider Generator" --git-email="noreply@crossplane.io"`,
-		cliMeta.CommandName, cliMeta.CommandName, cliMeta.CommandName, cliMeta.CommandName)
+    --git-name="Crossplane Provider Generator" --git-email="noreply@crossplane.io"
+
+  # Also offer a cluster-wide ClusterProviderConfig that any namespace can reference
+  %s init --domain=example.com --repo=github.com/example/provider-aws --cluster-provider-config
+
+  # License the provider under MIT, with your own copyright line
+  %s init --domain=acme.com --repo=github.com/acme/provider-acme --license=mit --copyright-owner="Acme Corp"
+
+  # Use your organization's Go file header
+  %s init --domain=acme.com --repo=github.com/acme/provider-acme --license=proprietary \
+    --boilerplate-file=../house/boilerplate.go.txt`,
+		cliMeta.CommandName, cliMeta.CommandName, cliMeta.CommandName, cliMeta.CommandName, cliMeta.CommandName,
+		cliMeta.CommandName, cliMeta.CommandName)
 }
 
 func (p *initSubcommand) BindFlags(fs *pflag.FlagSet) {
@@ -62,11 +84,26 @@ func (p *initSubcommand) BindFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&p.repo, "repo", "", "name to use for go module (e.g., github.com/user/repo)")
 	fs.StringVar(&p.gitName, "git-name", "", "git user name for commits (uses system config if not provided)")
 	fs.StringVar(&p.gitEmail, "git-email", "", "git user email for commits (uses system config if not provided)")
+	fs.BoolVar(&p.clusterProviderConfig, "cluster-provider-config", p.pluginConfig.Defaults.ClusterProviderConfig,
+		"also generate a cluster-scoped ClusterProviderConfig that managed resources in any namespace can reference")
+	fs.StringVar(&p.license, "license", p.pluginConfig.Defaults.License,
+		"license of the provider: "+strings.Join(core.Licenses, ", "))
+	fs.StringVar(&p.copyrightOwner, "copyright-owner", p.pluginConfig.Defaults.CopyrightOwner,
+		"copyright holder named in LICENSE and the Go file headers")
+	fs.IntVar(&p.copyrightYear, "copyright-year", time.Now().Year(), "year of the copyright line")
+	fs.StringVar(&p.boilerplateFile, "boilerplate-file", "",
+		"file holding the Go comment every generated Go file starts with, instead of the license's standard header")
 }
 
 func (p *initSubcommand) InjectConfig(c config.Config) error {
 	p.config = c
 	p.ensureConfig()
+	if p.configErr != nil {
+		return validation.InitError("generator settings", p.configErr)
+	}
+	// Recorded before the git identity is resolved: PROJECT keeps the
+	// configured fallback, not whoever happens to run init.
+	generator := p.pluginConfig.GeneratorSettings
 
 	// Resolve git configuration in priority order: CLI flags > System config > Project defaults
 	p.resolveGitConfig()
@@ -97,6 +134,31 @@ func (p *initSubcommand) InjectConfig(c config.Config) error {
 		return validation.InitError("configuration", err)
 	}
 
+	license := core.License{ID: p.license, Owner: p.copyrightOwner, Year: p.copyrightYear}
+	if p.boilerplateFile != "" {
+		b, err := os.ReadFile(p.boilerplateFile)
+		if err != nil {
+			return validation.InitError("license", err)
+		}
+		license.Boilerplate = strings.TrimSpace(string(b))
+	}
+	if err := license.Validate(); err != nil {
+		return validation.InitError("license", err)
+	}
+
+	// Recorded in PROJECT rather than passed to the templates directly, so that
+	// `update` renders the same ProviderConfig kinds, registries and headers later
+	// and applies none of the migrations to a layout that never needed them.
+	settings := core.ProjectSettings{
+		ClusterProviderConfig: p.clusterProviderConfig,
+		Generator:             generator,
+		License:               license,
+		Migrations:            migrationIDs(),
+	}
+	if err := core.SaveProjectSettings(p.config, settings); err != nil {
+		return validation.InitError("configuration", err)
+	}
+
 	return nil
 }
 
@@ -107,8 +169,12 @@ func (p *initSubcommand) PreScaffold(machinery.Filesystem) error {
 func (p *initSubcommand) Scaffold(fs machinery.Filesystem) error {
 	fmt.Printf("Scaffolding Crossplane provider project...\n")
 
+	locked := trackWrites(fs.FS)
 	scaffolder := scaffold.NewInitScaffolder(p.config)
-	return scaffolder.Scaffold(fs)
+	if err := scaffolder.Scaffold(machinery.Filesystem{FS: locked}); err != nil {
+		return err
+	}
+	return locked.saveLock()
 }
 
 func (p *initSubcommand) PostScaffold() error {
@@ -141,7 +207,10 @@ func (p *initSubcommand) PostScaffold() error {
 
 func (p *initSubcommand) ensureConfig() {
 	if p.pluginConfig == nil {
-		p.pluginConfig = NewPluginConfig()
+		p.pluginConfig, p.configErr = loadPluginConfig(nil)
+		if p.configErr != nil {
+			p.pluginConfig = NewPluginConfig()
+		}
 	}
 }
 
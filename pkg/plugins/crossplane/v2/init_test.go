@@ -0,0 +1,197 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/pflag"
+	cfgv3 "sigs.k8s.io/kubebuilder/v4/pkg/config/v3"
+	"sigs.k8s.io/kubebuilder/v4/pkg/machinery"
+
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/core"
+)
+
+func TestRenderClusterProviderConfig(t *testing.T) {
+	files := []string{
+		"apis/v1alpha1/types.go",
+		"apis/v1alpha1/register.go",
+		"internal/controller/config/config.go",
+		"internal/provider/connector.go",
+		"internal/provider/connector_test.go",
+		"examples/provider/config.yaml",
+	}
+	for _, enabled := range []bool{false, true} {
+		cfg := cfgv3.New()
+		_ = cfg.SetRepository("github.com/example/provider-test")
+		_ = cfg.SetDomain("example.com")
+		_ = cfg.SetProjectName("provider-test")
+		if err := core.SaveProjectSettings(cfg, core.ProjectSettings{ClusterProviderConfig: enabled}); err != nil {
+			t.Fatal(err)
+		}
+
+		mem := afero.NewMemMapFs()
+		if err := renderToMemFS(cfg, machinery.Filesystem{FS: mem}); err != nil {
+			t.Fatalf("render: %v", err)
+		}
+		for _, f := range files {
+			b, err := afero.ReadFile(mem, f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Contains(string(b), "ClusterProviderConfig"); got != enabled {
+				t.Errorf("clusterProviderConfig=%v: %s mentions ClusterProviderConfig = %v", enabled, f, got)
+			}
+		}
+	}
+}
+
+func TestInitRecordsGeneratorSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	_ = os.WriteFile(path, []byte("defaults:\n  domain: acme.io\n  registryOrgs: [xpkg.acme.io/acme]\n"+
+		"git:\n  buildSubmoduleRef: v0.1.0\n"), 0o644)
+	t.Setenv("XP_PROVIDER_GEN_CONFIG", path)
+	t.Setenv("XP_PROVIDER_GEN_REPO_PREFIX", "github.com/acme")
+
+	p := &initSubcommand{}
+	flags := pflag.NewFlagSet("init", pflag.ContinueOnError)
+	p.BindFlags(flags)
+	if err := flags.Parse([]string{"--repo=github.com/acme/provider-test", "--git-name=a", "--git-email=a@acme.io"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg := cfgv3.New()
+	if err := p.InjectConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.GetDomain(); got != "acme.io" {
+		t.Errorf("domain = %q, want the configured default", got)
+	}
+
+	settings, err := core.LoadProjectSettings(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	generator := settings.Generator
+	if generator.Defaults.RepoPrefix != "github.com/acme" || generator.Git.BuildSubmoduleRef != "v0.1.0" {
+		t.Errorf("recorded settings = %+v, want the config file and environment", generator)
+	}
+	if generator.Git.Author != "Crossplane Provider Generator" {
+		t.Errorf("recorded author = %q, want the fallback rather than --git-name", generator.Git.Author)
+	}
+	if due := dueMigrations(migrations, settings, "dev"); len(due) != 0 {
+		t.Errorf("a new project is due %d migration(s), want none", len(due))
+	}
+
+	// Later renders publish where the project was set up to.
+	mem := afero.NewMemMapFs()
+	if err := renderToMemFS(cfg, machinery.Filesystem{FS: mem}); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	makefile, _ := afero.ReadFile(mem, "Makefile")
+	for _, want := range []string{"XPKG_REG_ORGS ?= xpkg.acme.io/acme\n", "XPKG_REG_ORGS_NO_PROMOTE ?= \n"} {
+		if !strings.Contains(string(makefile), want) {
+			t.Errorf("Makefile does not contain %q", want)
+		}
+	}
+}
+
+func TestInitLicense(t *testing.T) {
+	t.Setenv("XP_PROVIDER_GEN_CONFIG", filepath.Join(t.TempDir(), "none.yaml"))
+	notice := "/*\n" + strings.Repeat("Copyright 2030 Acme Corp. All rights reserved. Internal use only.\n", 20) + "*/"
+	boilerplate := filepath.Join(t.TempDir(), "boilerplate.go.txt")
+	_ = os.WriteFile(boilerplate, []byte(notice+"\n"), 0o644)
+
+	render := func(t *testing.T, args ...string) afero.Fs {
+		t.Helper()
+		p := &initSubcommand{}
+		flags := pflag.NewFlagSet("init", pflag.ContinueOnError)
+		p.BindFlags(flags)
+		args = append(args, "--repo=github.com/acme/provider-test", "--git-name=a", "--git-email=a@acme.io")
+		if err := flags.Parse(args); err != nil {
+			t.Fatal(err)
+		}
+		cfg := cfgv3.New()
+		if err := p.InjectConfig(cfg); err != nil {
+			t.Fatal(err)
+		}
+		mem := afero.NewMemMapFs()
+		if err := renderToMemFS(cfg, machinery.Filesystem{FS: mem}); err != nil {
+			t.Fatalf("render: %v", err)
+		}
+		return mem
+	}
+	read := func(fs afero.Fs, path string) string {
+		b, _ := afero.ReadFile(fs, path)
+		return string(b)
+	}
+
+	mit := render(t, "--license=mit", "--copyright-owner=Acme Corp", "--copyright-year=2030")
+	for path, want := range map[string]string{
+		"LICENSE":                   "MIT License\n\nCopyright (c) 2030 Acme Corp\n",
+		"hack/boilerplate.go.txt":   "// SPDX-FileCopyrightText: 2030 Acme Corp\n//\n// SPDX-License-Identifier: MIT",
+		"package/crossplane.yaml":   "meta.crossplane.io/license: MIT\n",
+		"apis/v1alpha1/register.go": "/*\nCopyright 2030 Acme Corp.\n\nUse of this source code is governed by the MIT License",
+	} {
+		if got := read(mit, path); !strings.Contains(got, want) {
+			t.Errorf("--license=mit: %s does not contain %q:\n%s", path, want, got)
+		}
+	}
+
+	custom := render(t, "--license=proprietary", "--boilerplate-file="+boilerplate)
+	if got := read(custom, "hack/boilerplate.go.txt"); strings.TrimSpace(got) != notice {
+		t.Errorf("hack/boilerplate.go.txt = %q, want the boilerplate file", got)
+	}
+	register := read(custom, "apis/v1alpha1/register.go")
+	if !strings.HasPrefix(register, notice+"\n") {
+		t.Errorf("register.go does not start with the boilerplate file:\n%s", register)
+	}
+	if !core.IsToolOwned([]byte(register)) {
+		t.Error("register.go under a long boilerplate is not tool-owned")
+	}
+	if got := read(custom, "package/crossplane.yaml"); !strings.Contains(got, "license: LicenseRef-Proprietary\n") {
+		t.Errorf("crossplane.yaml does not name the proprietary license:\n%s", got)
+	}
+
+	// A project made before the license was recorded keeps its header.
+	cfg := cfgv3.New()
+	_ = cfg.SetRepository("github.com/example/provider-test")
+	mem := afero.NewMemMapFs()
+	if err := renderToMemFS(cfg, machinery.Filesystem{FS: mem}); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	legacy := "/*\nCopyright 2025 The Crossplane Authors.\n\nLicensed under the Apache License"
+	if got := read(mem, "apis/v1alpha1/register.go"); !strings.HasPrefix(got, legacy) {
+		t.Errorf("unrecorded license: register.go header changed:\n%s", got)
+	}
+
+	for _, args := range [][]string{{"--license=gpl"}, {"--boilerplate-file=" + filepath.Join(t.TempDir(), "missing")}} {
+		p := &initSubcommand{}
+		flags := pflag.NewFlagSet("init", pflag.ContinueOnError)
+		p.BindFlags(flags)
+		_ = flags.Parse(append(args, "--repo=github.com/acme/provider-test"))
+		if err := p.InjectConfig(cfgv3.New()); err == nil {
+			t.Errorf("init %v succeeded", args)
+		}
+	}
+	if err := (core.License{Boilerplate: "Copyright Acme Corp."}).Validate(); err == nil {
+		t.Error("a boilerplate that is not a Go comment was accepted")
+	}
+}
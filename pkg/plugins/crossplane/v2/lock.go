@@ -0,0 +1,136 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/core"
+)
+
+// lockedFs is the project directory as the generator writes to it: it remembers
+// every file opened for writing so saveLock can record what was written in
+// core.LockFile. Kubebuilder's scaffold and reconcile both write through
+// OpenFile, so wrapping the disk covers every path into the tree.
+type lockedFs struct {
+	afero.Fs
+	written []string
+}
+
+func trackWrites(disk afero.Fs) *lockedFs {
+	return &lockedFs{Fs: disk}
+}
+
+func (l *lockedFs) Create(name string) (afero.File, error) {
+	l.written = append(l.written, name)
+	return l.Fs.Create(name)
+}
+
+func (l *lockedFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR) != 0 {
+		l.written = append(l.written, name)
+	}
+	return l.Fs.OpenFile(name, flag, perm)
+}
+
+// saveLock records the written files in the lock, and drops the entries of
+// files that have since been deleted.
+func (l *lockedFs) saveLock() error {
+	lock, err := core.ReadLock(l.Fs)
+	if err != nil {
+		return err
+	}
+	for _, name := range l.written {
+		content, err := afero.ReadFile(l.Fs, name)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		lock.Record(strings.TrimPrefix(filepath.ToSlash(name), "/"), content)
+	}
+	if err := lock.Write(l.Fs); err != nil {
+		return fmt.Errorf("writing %s: %w", core.LockFile, err)
+	}
+	return nil
+}
+
+// driftedFile is a tool-owned file someone has edited since the generator wrote
+// it, with the diff reconcile would apply to it.
+type driftedFile struct {
+	path string
+	diff string
+}
+
+// findDrift returns the files reconcile would overwrite whose content on dst no
+// longer matches the lock. A file the update would leave as it is has not
+// drifted in any way that matters, so it is not reported.
+func findDrift(src, dst afero.Fs) ([]driftedFile, error) {
+	lock, err := core.ReadLock(dst)
+	if err != nil {
+		return nil, err
+	}
+	var drifted []driftedFile
+	err = afero.Walk(src, ".", func(path string, info fs.FileInfo, walkErr error) error {
+		if walkErr != nil || info.IsDir() {
+			return walkErr
+		}
+		rel := strings.TrimPrefix(filepath.ToSlash(path), "/")
+		if err := checkContained(rel); err != nil {
+			return err
+		}
+		exists, existing, err := readExisting(dst, rel)
+		if err != nil {
+			return err
+		}
+		if core.DecideWrite(exists, existing) != core.Overwrite || !lock.Drifted(rel, existing) {
+			return nil
+		}
+		rendered, err := afero.ReadFile(src, path)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(existing, rendered) {
+			drifted = append(drifted, driftedFile{
+				path: rel,
+				diff: unifiedDiff("a/"+rel, "b/"+rel, existing, rendered),
+			})
+		}
+		return nil
+	})
+	return drifted, err
+}
+
+// printDrift names the drifted files and shows what updating them would discard.
+func printDrift(out io.Writer, drifted []driftedFile) {
+	fmt.Fprintf(out, "%d tool-owned file(s) changed since xp-provider-gen wrote them:\n", len(drifted))
+	for _, d := range drifted {
+		fmt.Fprintf(out, "  %s\n", d.path)
+	}
+	for _, d := range drifted {
+		fmt.Fprint(out, d.diff)
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"sigs.k8s.io/kubebuilder/v4/pkg/machinery"
+
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/core"
+)
+
+func TestFindDrift(t *testing.T) {
+	const (
+		wiring   = "internal/controller/bucket/wiring.go"
+		register = "apis/register.go"
+		external = "internal/controller/bucket/external.go"
+	)
+	generated := core.GeneratedHeader + "\npackage bucket\n"
+	src := afero.NewMemMapFs()
+	dst := afero.NewMemMapFs()
+	for _, p := range []string{wiring, register} {
+		_ = afero.WriteFile(src, p, []byte(generated), 0o644)
+	}
+	_ = afero.WriteFile(src, external, []byte("package bucket\n"), 0o644)
+	if _, err := reconcile(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	if drifted, err := findDrift(src, dst); err != nil || len(drifted) != 0 {
+		t.Fatalf("findDrift() right after reconcile = %v, %v; want nothing", drifted, err)
+	}
+
+	// A teammate edits wiring.go and their own external.go; the generator moves on.
+	_ = afero.WriteFile(dst, wiring, []byte(generated+"// my fix\n"), 0o644)
+	_ = afero.WriteFile(dst, external, []byte("package bucket\n// mine\n"), 0o644)
+	newer := generated + "// newer generator\n"
+	_ = afero.WriteFile(src, wiring, []byte(newer), 0o644)
+	_ = afero.WriteFile(src, register, []byte(newer), 0o644)
+
+	drifted, err := findDrift(src, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drifted) != 1 || drifted[0].path != wiring {
+		t.Fatalf("findDrift() = %+v, want only %s", drifted, wiring)
+	}
+	if !strings.Contains(drifted[0].diff, "-// my fix\n") {
+		t.Errorf("diff does not show the edit update would discard:\n%s", drifted[0].diff)
+	}
+
+	var out bytes.Buffer
+	printDrift(&out, drifted)
+	if !strings.Contains(out.String(), "  "+wiring+"\n") {
+		t.Errorf("printDrift() does not name %s:\n%s", wiring, out.String())
+	}
+
+	// Overwriting (update --force) records the new content, so nothing drifts after.
+	if _, err := reconcile(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	if drifted, _ := findDrift(src, dst); len(drifted) != 0 {
+		t.Errorf("findDrift() after overwriting = %+v, want nothing", drifted)
+	}
+}
+
+func TestFindDriftWithoutLock(t *testing.T) {
+	src := afero.NewMemMapFs()
+	dst := afero.NewMemMapFs()
+	_ = afero.WriteFile(src, "apis/register.go", []byte(core.GeneratedHeader+"\npackage apis\n"), 0o644)
+	_ = afero.WriteFile(dst, "apis/register.go", []byte(core.GeneratedHeader+"\npackage apis\n// old\n"), 0o644)
+
+	// A provider made before the lock existed has nothing to compare against.
+	if drifted, err := findDrift(src, dst); err != nil || len(drifted) != 0 {
+		t.Errorf("findDrift() without a lock = %v, %v; want nothing", drifted, err)
+	}
+}
+
+func TestLockedFsRecordsScaffold(t *testing.T) {
+	disk := afero.NewMemMapFs()
+	locked := trackWrites(disk)
+	if err := renderToMemFS(webhookProject(t, nil), machinery.Filesystem{FS: locked}); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if err := locked.saveLock(); err != nil {
+		t.Fatal(err)
+	}
+
+	lock, err := core.ReadLock(disk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := lock["internal/controller/bucket/wiring.go"]; !ok {
+		t.Errorf("tool-owned wiring.go is not locked: %v", lock)
+	}
+	if _, ok := lock["internal/controller/bucket/external.go"]; ok {
+		t.Error("user-owned external.go is locked")
+	}
+}
@@ -0,0 +1,309 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/spf13/afero"
+	"golang.org/x/mod/semver"
+	"sigs.k8s.io/kubebuilder/v4/pkg/config"
+
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/core"
+)
+
+// migration carries a provider across one change to the generated layout that
+// re-rendering cannot make on its own: a file renamed or retired, or a seam whose
+// signature changed in user-owned code. `update` applies the ones a project is
+// due before it renders, and records each in PROJECT so none runs twice.
+type migration struct {
+	// id names the migration in PROJECT once applied. Never change or reuse one.
+	id string
+
+	// since is the first generator version with the new layout. A project
+	// stamped with an older version, or with none, is due. Empty means every
+	// project is due, for changes made before releases were tagged.
+	since string
+
+	// summary is the one line `update` prints when it applies the migration.
+	summary string
+
+	// run applies the migration. A project without a stamp may already have
+	// the new layout, so run checks for what it changes rather than assuming.
+	run func(m *migrationEnv) error
+}
+
+// migrations is every migration, oldest first. Append to it; never reorder.
+var migrations = []migration{
+	{
+		id:      "modular-layout",
+		since:   "v1.0.0",
+		summary: "per-kind setup.go becomes wiring.go; controller.go gives way to external.go",
+		run:     migrateModularLayout,
+	},
+}
+
+// migrationIDs returns the ID of every migration. A project `init` creates
+// already has the layout each one moves to, so it records them all.
+func migrationIDs() []string {
+	ids := make([]string, 0, len(migrations))
+	for _, mig := range migrations {
+		ids = append(ids, mig.id)
+	}
+	return ids
+}
+
+// migrationEnv is what a migration works on: the project directory, its
+// PROJECT, and notes for whatever a person has to finish by hand.
+type migrationEnv struct {
+	cfg   config.Config
+	disk  afero.Fs
+	notes []string
+
+	// preview makes rename, remove and rewriteGo only record that they would
+	// change the project, for `update --dry-run`.
+	preview bool
+	// changed is set once the migration has changed, or in preview would
+	// change, a file.
+	changed bool
+}
+
+// rename moves a project file, refusing to replace one that already exists.
+func (m *migrationEnv) rename(from, to string) error {
+	for _, p := range []string{from, to} {
+		if err := checkContained(p); err != nil {
+			return err
+		}
+	}
+	exists, err := afero.Exists(m.disk, to)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("cannot rename %s: %s already exists", from, to)
+	}
+	m.changed = true
+	if m.preview {
+		return nil
+	}
+	return m.disk.Rename(from, to)
+}
+
+// remove deletes a project file.
+func (m *migrationEnv) remove(p string) error {
+	if err := checkContained(p); err != nil {
+		return err
+	}
+	m.changed = true
+	if m.preview {
+		return nil
+	}
+	return m.disk.Remove(p)
+}
+
+// rewriteGo parses the Go file p and hands its syntax tree to edit, which
+// reports whether it changed anything; if it did, the file is written back
+// formatted. Migrations use it to carry a seam's new signature into the
+// user's code.
+func (m *migrationEnv) rewriteGo(p string, edit func(*ast.File) bool) error {
+	if err := checkContained(p); err != nil {
+		return err
+	}
+	src, err := afero.ReadFile(m.disk, p)
+	if err != nil {
+		return err
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, p, src, parser.ParseComments)
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", p, err)
+	}
+	if !edit(f) {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, f); err != nil {
+		return fmt.Errorf("formatting %s: %w", p, err)
+	}
+	m.changed = true
+	if m.preview {
+		return nil
+	}
+	return afero.WriteFile(m.disk, p, buf.Bytes(), 0o644)
+}
+
+// note records something the migration could not do itself.
+func (m *migrationEnv) note(msg string, args ...any) {
+	m.notes = append(m.notes, fmt.Sprintf(msg, args...))
+}
+
+// dueMigrations returns, in order, the migrations from registry that a project
+// with the given settings has not had and that the running generator knows to
+// apply. A version that is not semver ("dev", a bare commit) counts as older
+// than any release when stamped, and newer than any when running.
+func dueMigrations(registry []migration, settings core.ProjectSettings, running string) []migration {
+	stamped := canonicalVersion(settings.Version)
+	var due []migration
+	for _, mig := range registry {
+		if settings.Migrated(mig.id) {
+			continue
+		}
+		if since := canonicalVersion(mig.since); since != "" && stamped != "" && semver.Compare(stamped, since) >= 0 {
+			continue // made by a generator that already had this layout
+		}
+		if newerThan(mig, running) {
+			continue // a migration for a generator newer than this one
+		}
+		due = append(due, mig)
+	}
+	return due
+}
+
+// canonicalVersion returns v as semver, adding the "v" that release tags may
+// omit, or "" when it is not a version at all.
+func canonicalVersion(v string) string {
+	if v != "" && !strings.HasPrefix(v, "v") {
+		v = "v" + v
+	}
+	if !semver.IsValid(v) {
+		return ""
+	}
+	return v
+}
+
+// runMigrations applies the migrations the project is due, in order, recording
+// each in cfg's settings once it succeeds. The caller saves PROJECT.
+func runMigrations(cfg config.Config, disk afero.Fs, running string, out io.Writer) error {
+	settings, err := core.LoadProjectSettings(cfg)
+	if err != nil {
+		return err
+	}
+	for _, mig := range dueMigrations(migrations, settings, running) {
+		fmt.Fprintf(out, "Applying migration %s: %s\n", mig.id, mig.summary)
+		env := &migrationEnv{cfg: cfg, disk: disk}
+		if err := mig.run(env); err != nil {
+			return fmt.Errorf("migration %s: %w", mig.id, err)
+		}
+		for _, n := range env.notes {
+			fmt.Fprintf(out, "  ACTION NEEDED: %s\n", n)
+		}
+		settings.Migrations = append(settings.Migrations, mig.id)
+		if err := core.SaveProjectSettings(cfg, settings); err != nil {
+			return err
+		}
+	}
+
+	// The project now has the running generator's layout, so the migrations it
+	// was not due are recorded too; an update by a later generator must not run
+	// them against it.
+	settled := false
+	for _, mig := range migrations {
+		if !settings.Migrated(mig.id) && !newerThan(mig, running) {
+			settings.Migrations = append(settings.Migrations, mig.id)
+			settled = true
+		}
+	}
+	if !settled {
+		return nil
+	}
+	return core.SaveProjectSettings(cfg, settings)
+}
+
+// newerThan reports whether mig is for a generator newer than running. A
+// running version that is not semver is newer than any.
+func newerThan(mig migration, running string) bool {
+	since, current := canonicalVersion(mig.since), canonicalVersion(running)
+	return since != "" && current != "" && semver.Compare(current, since) < 0
+}
+
+// pendingMigrations returns the migrations the project is due that would
+// change a file or leave a note, running each in preview. A project that is
+// due one only because it carries no record of it, such as one already on the
+// new layout, has nothing pending.
+func pendingMigrations(cfg config.Config, disk afero.Fs, running string) ([]migration, error) {
+	settings, err := core.LoadProjectSettings(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var pending []migration
+	for _, mig := range dueMigrations(migrations, settings, running) {
+		env := &migrationEnv{cfg: cfg, disk: disk, preview: true}
+		if err := mig.run(env); err != nil {
+			return nil, fmt.Errorf("migration %s: %w", mig.id, err)
+		}
+		if env.changed || len(env.notes) > 0 {
+			pending = append(pending, mig)
+		}
+	}
+	return pending, nil
+}
+
+// migrateModularLayout moves a provider made before the modular layout. Each
+// kind's tool-owned setup.go becomes wiring.go, which the update then
+// re-renders. The kind's controller.go holds the user's code, so it is left for
+// them to fold into the external.go the update seeds beside it.
+func migrateModularLayout(m *migrationEnv) error {
+	resources, err := m.cfg.GetResources()
+	if err != nil {
+		return fmt.Errorf("reading project resources: %w", err)
+	}
+	seen := map[string]bool{}
+	for _, res := range resources {
+		dir := path.Join("internal/controller", strings.ToLower(res.Kind))
+		setup := path.Join(dir, "setup.go")
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		b, err := afero.ReadFile(m.disk, setup)
+		if os.IsNotExist(err) {
+			continue // already on the modular layout
+		}
+		if err != nil {
+			return err
+		}
+		if !core.IsToolOwned(b) {
+			m.note("%s has no generated header, so it was left in place; delete it once "+
+				"wiring.go's Setup has replaced it", setup)
+			continue
+		}
+		wiring := path.Join(dir, "wiring.go")
+		if exists, _ := afero.Exists(m.disk, wiring); exists {
+			err = m.remove(setup)
+		} else {
+			err = m.rename(setup, wiring)
+		}
+		if err != nil {
+			return err
+		}
+		if exists, _ := afero.Exists(m.disk, path.Join(dir, "controller.go")); exists {
+			m.note("move the external client in %[1]s/controller.go into %[1]s/external.go "+
+				"(NewExternal and its methods), then delete controller.go; the connector it "+
+				"defines is now internal/provider/connector.go", dir)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"bytes"
+	"go/ast"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/core"
+)
+
+func TestDueMigrations(t *testing.T) {
+	registry := []migration{
+		{id: "unversioned"},
+		{id: "in-0.5", since: "v0.5.0"},
+		{id: "in-0.9", since: "0.9.0"},
+	}
+	cases := map[string]struct {
+		settings core.ProjectSettings
+		running  string
+		want     []string
+	}{
+		"UnstampedDevBuild": {
+			running: "dev",
+			want:    []string{"unversioned", "in-0.5", "in-0.9"},
+		},
+		"StampedBetween": {
+			settings: core.ProjectSettings{Version: "v0.5.0"},
+			running:  "v0.8.0",
+			want:     []string{"unversioned"},
+		},
+		"StampedBefore": {
+			settings: core.ProjectSettings{Version: "v0.4.1"},
+			running:  "0.9.0",
+			want:     []string{"unversioned", "in-0.5", "in-0.9"},
+		},
+		"AlreadyApplied": {
+			settings: core.ProjectSettings{Version: "v0.4.1", Migrations: []string{"unversioned", "in-0.5"}},
+			running:  "v1.0.0",
+			want:     []string{"in-0.9"},
+		},
+		"StampedByDevBuild": {
+			settings: core.ProjectSettings{Version: "dev"},
+			running:  "v0.6.0-3-gabc1234",
+			want:     []string{"unversioned", "in-0.5"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got []string
+			for _, m := range dueMigrations(registry, tc.settings, tc.running) {
+				got = append(got, m.id)
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("dueMigrations() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMigrateModularLayout(t *testing.T) {
+	cfg := webhookProject(t, nil)
+	disk := afero.NewMemMapFs()
+	setup := []byte(core.GeneratedHeader + "\npackage bucket\n\nfunc Setup() {}\n")
+	controller := []byte("package bucket\n\ntype external struct{}\n")
+	_ = afero.WriteFile(disk, "internal/controller/bucket/setup.go", setup, 0o644)
+	_ = afero.WriteFile(disk, "internal/controller/bucket/controller.go", controller, 0o644)
+
+	var out bytes.Buffer
+	if err := runMigrations(cfg, disk, "dev", &out); err != nil {
+		t.Fatal(err)
+	}
+	if exists, _ := afero.Exists(disk, "internal/controller/bucket/setup.go"); exists {
+		t.Error("setup.go is still there")
+	}
+	if b, _ := afero.ReadFile(disk, "internal/controller/bucket/wiring.go"); !bytes.Equal(b, setup) {
+		t.Errorf("wiring.go = %q, want setup.go moved there", b)
+	}
+	if b, _ := afero.ReadFile(disk, "internal/controller/bucket/controller.go"); !bytes.Equal(b, controller) {
+		t.Errorf("user-owned controller.go changed: %q", b)
+	}
+	if !strings.Contains(out.String(), "ACTION NEEDED: move the external client in internal/controller/bucket/controller.go") {
+		t.Errorf("no note about controller.go:\n%s", out.String())
+	}
+
+	settings, _ := core.LoadProjectSettings(cfg)
+	if !settings.Migrated("modular-layout") {
+		t.Errorf("migration not recorded: %+v", settings)
+	}
+	out.Reset()
+	if err := runMigrations(cfg, disk, "dev", &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("a recorded migration ran again:\n%s", out.String())
+	}
+}
+
+func TestPendingMigrations(t *testing.T) {
+	cfg := webhookProject(t, nil)
+	disk := afero.NewMemMapFs()
+	wiring := []byte(core.GeneratedHeader + "\npackage bucket\n\nfunc Setup() {}\n")
+	_ = afero.WriteFile(disk, "internal/controller/bucket/wiring.go", wiring, 0o644)
+
+	// Unstamped and unrecorded, so due, but already on the modular layout.
+	pending, err := pendingMigrations(cfg, disk, "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("pendingMigrations() = %d migration(s) for a project already migrated, want none", len(pending))
+	}
+
+	_ = afero.WriteFile(disk, "internal/controller/bucket/setup.go", wiring, 0o644)
+	pending, err = pendingMigrations(cfg, disk, "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].id != "modular-layout" {
+		t.Errorf("pendingMigrations() = %v, want modular-layout", pending)
+	}
+	if exists, _ := afero.Exists(disk, "internal/controller/bucket/setup.go"); !exists {
+		t.Error("a preview removed setup.go")
+	}
+
+	m := &migrationEnv{disk: disk, preview: true}
+	if err := m.rename("internal/controller/bucket/setup.go", "../setup.go"); err == nil {
+		t.Error("rename() moved a file out of the project")
+	}
+}
+
+func TestRunMigrationsSettles(t *testing.T) {
+	cfg := webhookProject(t, nil)
+	settings := core.ProjectSettings{Version: "v1.2.0"}
+	if err := core.SaveProjectSettings(cfg, settings); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := runMigrations(cfg, afero.NewMemMapFs(), "v1.3.0", &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("a migration ran on a project that was not due it:\n%s", out.String())
+	}
+	settings, _ = core.LoadProjectSettings(cfg)
+	if !slices.Equal(settings.Migrations, migrationIDs()) {
+		t.Errorf("recorded migrations = %v, want every one, %v", settings.Migrations, migrationIDs())
+	}
+}
+
+func TestMigrationEnvRewriteGo(t *testing.T) {
+	const path = "internal/controller/bucket/external.go"
+	src := "package bucket\n\n// Setup is the seam.\nfunc Setup() error { return nil }\n"
+	disk := afero.NewMemMapFs()
+	_ = afero.WriteFile(disk, path, []byte(src), 0o644)
+	rename := func(f *ast.File) bool {
+		for _, d := range f.Decls {
+			if fn, ok := d.(*ast.FuncDecl); ok && fn.Name.Name == "Setup" {
+				fn.Name.Name = "SetupGated"
+				return true
+			}
+		}
+		return false
+	}
+
+	preview := &migrationEnv{disk: disk, preview: true}
+	if err := preview.rewriteGo(path, rename); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := afero.ReadFile(disk, path); string(b) != src || !preview.changed {
+		t.Errorf("preview: changed = %v, file = %q; want a change recorded and the file untouched", preview.changed, b)
+	}
+
+	m := &migrationEnv{disk: disk}
+	if err := m.rewriteGo(path, rename); err != nil {
+		t.Fatal(err)
+	}
+	want := "package bucket\n\n// Setup is the seam.\nfunc SetupGated() error { return nil }\n"
+	if b, _ := afero.ReadFile(disk, path); string(b) != want {
+		t.Errorf("rewritten file = %q, want %q", b, want)
+	}
+
+	untouched := &migrationEnv{disk: disk}
+	if err := untouched.rewriteGo(path, rename); err != nil {
+		t.Fatal(err)
+	}
+	if untouched.changed {
+		t.Error("an edit that changed nothing was recorded as a change")
+	}
+	if err := m.rewriteGo("../external.go", rename); err == nil {
+		t.Error("rewriteGo() edited a file outside the project")
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openapi
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// scalars are the types a declared field may have, besides lists and maps of
+// them, as the schemas they stand for.
+var scalars = map[string]Schema{
+	"string": {Type: "string"},
+	"bool":   {Type: "boolean"},
+	"int":    {Type: "integer"},
+	"int64":  {Type: "integer"},
+	"int32":  {Type: "integer", Format: "int32"},
+	"number": {Type: "number"},
+}
+
+// declaredName is the form of a declared field's name: a JSON name as
+// Kubernetes APIs write them.
+var declaredName = regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`)
+
+// Declare returns the model of fields declared as name:type[,required][,immutable]:
+// each of params is a field of kind's Parameters, and each of observe, which
+// takes no options, one of its Observation.
+func Declare(kind string, params, observe []string) (*Model, error) {
+	spec, err := declaredObject(params, false)
+	if err != nil {
+		return nil, err
+	}
+	status, err := declaredObject(observe, true)
+	if err != nil {
+		return nil, err
+	}
+
+	b := newBuilder(&Document{}, kind)
+	m := &Model{}
+	if m.Parameters, err = b.fields(spec, parameters, true, kind); err != nil {
+		return nil, err
+	}
+	if m.Observation, err = b.fields(status, observation, true, kind); err != nil {
+		return nil, err
+	}
+	if err := m.setSample(b.sampleObject(spec)); err != nil {
+		return nil, fmt.Errorf("rendering the sample of %s: %w", kind, err)
+	}
+	return m, nil
+}
+
+// declaredObject returns the object whose properties are the declared fields;
+// observed fields are read-only.
+func declaredObject(decls []string, observed bool) (*Schema, error) {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for _, decl := range decls {
+		name, p, required, err := parseField(decl)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := s.Properties[name]; ok {
+			return nil, fmt.Errorf("field %s is declared twice", name)
+		}
+		if observed && (required || p.immutable) {
+			return nil, fmt.Errorf("field %q: observed fields take no options", decl)
+		}
+		p.ReadOnly = observed
+		s.Properties[name] = p
+		if required {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return s, nil
+}
+
+// parseField parses one name:type[,option...] declaration.
+func parseField(decl string) (string, *Schema, bool, error) {
+	name, rest, ok := strings.Cut(decl, ":")
+	if !ok {
+		return "", nil, false, fmt.Errorf("field %q is not name:type", decl)
+	}
+	if !declaredName.MatchString(name) {
+		return "", nil, false, fmt.Errorf("field %q: the name must be lowerCamelCase, such as instanceType", decl)
+	}
+	options := strings.Split(rest, ",")
+	p, err := declaredType(options[0])
+	if err != nil {
+		return "", nil, false, fmt.Errorf("field %q: %w", decl, err)
+	}
+	var required bool
+	for _, option := range options[1:] {
+		switch option {
+		case "required":
+			required = true
+		case "immutable":
+			p.immutable = true
+		default:
+			return "", nil, false, fmt.Errorf("field %q: unknown option %q; the options are required and immutable",
+				decl, option)
+		}
+	}
+	return name, p, required, nil
+}
+
+// declaredType returns the schema of a declared type: a scalar, or []T or
+// map[string]T of a declared type.
+func declaredType(typ string) (*Schema, error) {
+	if elem, ok := strings.CutPrefix(typ, "[]"); ok {
+		items, err := declaredType(elem)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	}
+	if elem, ok := strings.CutPrefix(typ, "map[string]"); ok {
+		values, err := declaredType(elem)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "object", AdditionalProperties: &additionalProperties{Schema: values, Allowed: true}}, nil
+	}
+	s, ok := scalars[typ]
+	if !ok {
+		return nil, fmt.Errorf("unknown type %q; use string, bool, int, int32, int64 or number, "+
+			"or a []T or map[string]T of them", typ)
+	}
+	return &s, nil
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package openapi turns a schema in an OpenAPI 3 document, or fields declared
+// on the command line, into the Go types of a managed resource. It reads only
+// what that needs: component schemas, local references, and the validation
+// keywords CRDs can express.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// schemasRef is the prefix of a reference to a component schema.
+const schemasRef = "#/components/schemas/"
+
+// Document is the part of an OpenAPI 3 document the generator reads.
+type Document struct {
+	OpenAPI    string `json:"openapi"`
+	Swagger    string `json:"swagger"`
+	Components struct {
+		Schemas map[string]*Schema `json:"schemas"`
+	} `json:"components"`
+}
+
+// Schema is an OpenAPI schema object, reduced to the keywords that decide a
+// Go type, its validation markers, and a sample value.
+type Schema struct {
+	Ref         string     `json:"$ref"`
+	Type        schemaType `json:"type"`
+	Format      string     `json:"format"`
+	Description string     `json:"description"`
+
+	Properties           map[string]*Schema    `json:"properties"`
+	Required             []string              `json:"required"`
+	AdditionalProperties *additionalProperties `json:"additionalProperties"`
+	Items                *Schema               `json:"items"`
+	AllOf                []*Schema             `json:"allOf"`
+	OneOf                []*Schema             `json:"oneOf"`
+	AnyOf                []*Schema             `json:"anyOf"`
+
+	ReadOnly  bool `json:"readOnly"`
+	WriteOnly bool `json:"writeOnly"`
+
+	Enum      []any    `json:"enum"`
+	Minimum   *float64 `json:"minimum"`
+	Maximum   *float64 `json:"maximum"`
+	MinLength *int64   `json:"minLength"`
+	MaxLength *int64   `json:"maxLength"`
+	MinItems  *int64   `json:"minItems"`
+	MaxItems  *int64   `json:"maxItems"`
+	Pattern   string   `json:"pattern"`
+
+	Default any `json:"default"`
+	Example any `json:"example"`
+
+	// immutable is set on fields declared immutable on the command line;
+	// OpenAPI has no keyword for it.
+	immutable bool
+}
+
+// schemaType is a schema's type. OpenAPI 3.1 allows a list, where "null"
+// only marks the value nullable; the other entry is the type.
+type schemaType string
+
+func (t *schemaType) UnmarshalJSON(b []byte) error {
+	var one string
+	if err := json.Unmarshal(b, &one); err == nil {
+		*t = schemaType(one)
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(b, &many); err != nil {
+		return err
+	}
+	for _, typ := range many {
+		if typ != "null" {
+			*t = schemaType(typ)
+		}
+	}
+	return nil
+}
+
+// additionalProperties is either a schema for a map's values or a boolean;
+// true is a map of anything, as is an empty schema.
+type additionalProperties struct {
+	Schema  *Schema
+	Allowed bool
+}
+
+func (a *additionalProperties) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, &a.Allowed); err == nil {
+		return nil
+	}
+	a.Allowed = true
+	return json.Unmarshal(b, &a.Schema)
+}
+
+// Load reads an OpenAPI 3 document, in YAML or JSON, from path.
+func Load(path string) (*Document, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc Document
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		if doc.Swagger != "" {
+			return nil, fmt.Errorf("%s is a Swagger %s document; only OpenAPI 3 is supported", path, doc.Swagger)
+		}
+		return nil, fmt.Errorf("%s is not an OpenAPI 3 document: it has no `openapi: 3.x` field", path)
+	}
+	return &doc, nil
+}
+
+// Lookup returns the component schema a reference names, and its name. The
+// reference is "#/components/schemas/<name>", or just the name.
+func (d *Document) Lookup(ref string) (string, *Schema, error) {
+	name := ref
+	if strings.Contains(ref, "#") || strings.Contains(ref, "/") {
+		var ok bool
+		if name, ok = strings.CutPrefix(ref, schemasRef); !ok || strings.Contains(name, "/") {
+			return "", nil, fmt.Errorf("reference %q is not supported; only %s<name> references are", ref, schemasRef)
+		}
+	}
+	s, ok := d.Components.Schemas[name]
+	if !ok || s == nil {
+		return "", nil, fmt.Errorf("the document has no schema %s%s", schemasRef, name)
+	}
+	return name, s, nil
+}
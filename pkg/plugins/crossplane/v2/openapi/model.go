@@ -0,0 +1,477 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openapi
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Model is a schema as the Go types of a managed resource: its writable
+// properties are the kind's Parameters and its read-only ones its Observation.
+// The types template renders it, and the manifests take their sample from it.
+type Model struct {
+	// Description is the schema's description, a line at a time.
+	Description []string
+
+	Parameters  []Field
+	Observation []Field
+
+	// Structs are the types of nested objects, in the order they are first
+	// used. An object used on both sides has a Parameters and an Observation
+	// type, since only the latter has the read-only properties.
+	Structs []Struct
+
+	// RawExtension is set when some field is free-form: a oneOf, an anyOf, or
+	// a value the schema leaves untyped. Those are runtime.RawExtension.
+	RawExtension bool
+
+	// sample is spec.forProvider with a valid value for every writable
+	// property a value can be found for, as YAML.
+	sample string
+	// refs are the sample's references, by field: only the example manifest
+	// has them, since it sits beside the manifests of the kinds they name.
+	refs map[string]any
+	// values is the sample before rendering, which refs are added to.
+	values map[string]any
+}
+
+// Struct is a nested object type.
+type Struct struct {
+	Name   string
+	Doc    []string
+	Fields []Field
+}
+
+// Field is a struct field.
+type Field struct {
+	Name     string
+	JSONName string
+	Type     string
+	Required bool
+
+	// Comments are the lines of the field's doc comment: the property's
+	// description, then its kubebuilder markers.
+	Comments []string
+}
+
+// Tag returns the field's struct tag. Optional fields are omitted when empty.
+func (f Field) Tag() string {
+	if f.Required {
+		return fmt.Sprintf("json:%q", f.JSONName)
+	}
+	return fmt.Sprintf("json:%q", f.JSONName+",omitempty")
+}
+
+// ForProviderYAML returns spec.forProvider with sample values, as a YAML
+// mapping entry indented by indent spaces.
+func (m *Model) ForProviderYAML(indent int) string {
+	return forProviderYAML(m.sample, indent)
+}
+
+// ExampleForProviderYAML is ForProviderYAML with the sample's references too,
+// each to the object named example, as the example manifests name them.
+func (m *Model) ExampleForProviderYAML(indent int) string {
+	if len(m.refs) == 0 {
+		return m.ForProviderYAML(indent)
+	}
+	values := maps.Clone(m.values)
+	if values == nil {
+		values = map[string]any{}
+	}
+	maps.Copy(values, m.refs)
+	out, err := yaml.Marshal(values)
+	if err != nil {
+		return m.ForProviderYAML(indent)
+	}
+	return forProviderYAML(strings.TrimSuffix(string(out), "\n"), indent)
+}
+
+func forProviderYAML(sample string, indent int) string {
+	pad := strings.Repeat(" ", indent)
+	if sample == "" {
+		return pad + "forProvider: {}"
+	}
+	lines := strings.Split(sample, "\n")
+	for i, line := range lines {
+		lines[i] = pad + "  " + line
+	}
+	return pad + "forProvider:\n" + strings.Join(lines, "\n")
+}
+
+// side is which of a managed resource's halves a type belongs to.
+type side int
+
+const (
+	parameters side = iota
+	observation
+)
+
+func (s side) String() string {
+	if s == observation {
+		return "Observation"
+	}
+	return "Parameters"
+}
+
+// Build returns the model of the schema ref names in doc, for kind; nested
+// types are named after kind so that kinds in one package do not collide.
+func Build(doc *Document, ref, kind string) (*Model, error) {
+	name, s, err := doc.Lookup(ref)
+	if err != nil {
+		return nil, err
+	}
+	b := newBuilder(doc, kind)
+	root, err := b.resolve(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(root.Properties) == 0 {
+		return nil, fmt.Errorf("schema %s has no properties to generate fields from", name)
+	}
+
+	m := &Model{Description: lines(root.Description)}
+	if m.Parameters, err = b.fields(root, parameters, true, kind); err != nil {
+		return nil, err
+	}
+	if m.Observation, err = b.fields(root, observation, true, kind); err != nil {
+		return nil, err
+	}
+	m.Structs, m.RawExtension = b.structs, b.raw
+	if err := m.setSample(b.sampleObject(root)); err != nil {
+		return nil, fmt.Errorf("rendering the sample of %s: %w", name, err)
+	}
+	return m, nil
+}
+
+// setSample sets the model's sample of spec.forProvider.
+func (m *Model) setSample(sample map[string]any) error {
+	if len(sample) == 0 {
+		return nil
+	}
+	out, err := yaml.Marshal(sample)
+	if err != nil {
+		return err
+	}
+	m.sample, m.values = strings.TrimSuffix(string(out), "\n"), sample
+	return nil
+}
+
+type builder struct {
+	doc  *Document
+	kind string
+
+	structs []Struct
+	// named maps an object — a side and its reference or path — to the name
+	// of its type; taken holds every name given out.
+	named map[string]string
+	taken map[string]bool
+	// visiting holds the objects whose types are being built, to refuse a
+	// schema that contains itself: a CRD cannot describe one.
+	visiting map[string]bool
+	raw      bool
+}
+
+func newBuilder(doc *Document, kind string) *builder {
+	return &builder{
+		doc:      doc,
+		kind:     kind,
+		named:    map[string]string{},
+		taken:    map[string]bool{kind + "Parameters": true, kind + "Observation": true},
+		visiting: map[string]bool{},
+	}
+}
+
+// resolve follows s's reference and merges its allOf, so the result has every
+// property itself. What s says beside its reference — a description, readOnly
+// — wins over the target's.
+func (b *builder) resolve(s *Schema) (*Schema, error) {
+	out := *s
+	for hops := 0; out.Ref != ""; hops++ {
+		if hops > len(b.doc.Components.Schemas) {
+			return nil, fmt.Errorf("reference %s is circular", s.Ref)
+		}
+		_, target, err := b.doc.Lookup(out.Ref)
+		if err != nil {
+			return nil, err
+		}
+		description, readOnly, writeOnly := out.Description, out.ReadOnly, out.WriteOnly
+		out = *target
+		if description != "" {
+			out.Description = description
+		}
+		out.ReadOnly = out.ReadOnly || readOnly
+		out.WriteOnly = out.WriteOnly || writeOnly
+	}
+	if len(out.AllOf) == 0 {
+		return &out, nil
+	}
+
+	all := out.AllOf
+	out.AllOf = nil
+	props := map[string]*Schema{}
+	for name, p := range out.Properties {
+		props[name] = p
+	}
+	for _, sub := range all {
+		part, err := b.resolve(sub)
+		if err != nil {
+			return nil, err
+		}
+		for name, p := range part.Properties {
+			props[name] = p
+		}
+		out.Required = append(out.Required, part.Required...)
+		if out.Type == "" {
+			out.Type = part.Type
+		}
+		if out.Description == "" {
+			out.Description = part.Description
+		}
+	}
+	out.Properties = props
+	return &out, nil
+}
+
+// fields returns the fields of object s for a side. At the top, the
+// Observation has only the read-only properties; below it, an observed object
+// has all of its own. Parameters never have them, and the Observation never
+// has write-only ones.
+func (b *builder) fields(s *Schema, sd side, top bool, prefix string) ([]Field, error) {
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if a, b := strings.ToLower(names[i]), strings.ToLower(names[j]); a != b {
+			return a < b
+		}
+		return names[i] < names[j]
+	})
+
+	var fields []Field
+	used := map[string]bool{}
+	for _, name := range names {
+		raw := s.Properties[name]
+		p, err := b.resolve(raw)
+		if err != nil {
+			return nil, fmt.Errorf("property %s: %w", name, err)
+		}
+		switch {
+		case sd == parameters && p.ReadOnly,
+			sd == observation && p.WriteOnly,
+			sd == observation && top && !p.ReadOnly:
+			continue
+		}
+
+		typ, err := b.goType(raw, sd, prefix+goName(name), "the "+name+" property")
+		if err != nil {
+			return nil, fmt.Errorf("property %s: %w", name, err)
+		}
+		f := Field{
+			Name:     unique(goName(name), used),
+			JSONName: name,
+			Type:     typ,
+			// Observed values are reported by the provider, which cannot
+			// promise any of them before the resource exists.
+			Required: sd == parameters && slices.Contains(s.Required, name),
+		}
+		if !f.Required && !strings.HasPrefix(typ, "[]") && !strings.HasPrefix(typ, "map[") {
+			f.Type = "*" + typ
+		}
+		f.Comments = append(lines(p.Description), markers(p, f.Required)...)
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// goType returns the Go type of schema raw. hint names the type of an object
+// defined in place; what names it in that type's doc comment.
+func (b *builder) goType(raw *Schema, sd side, hint, what string) (string, error) {
+	p, err := b.resolve(raw)
+	if err != nil {
+		return "", err
+	}
+	key := hint
+	if name, ok := strings.CutPrefix(raw.Ref, schemasRef); ok {
+		hint, what, key = b.kind+goName(name), "a "+name, raw.Ref
+	}
+
+	switch {
+	case len(p.OneOf) > 0 || len(p.AnyOf) > 0:
+		return b.rawExtension(), nil
+	case p.Type == "array" || p.Type == "" && p.Items != nil:
+		if p.Items == nil {
+			return "[]" + b.rawExtension(), nil
+		}
+		elem, err := b.goType(p.Items, sd, hint, what)
+		return "[]" + elem, err
+	case p.Type == "object" || p.Type == "" && len(p.Properties) > 0:
+		if len(p.Properties) > 0 {
+			return b.structType(sd.String()+" "+key, p, sd, hint, what)
+		}
+		if ap := p.AdditionalProperties; ap != nil && ap.Schema != nil && !reflect.ValueOf(*ap.Schema).IsZero() {
+			elem, err := b.goType(ap.Schema, sd, hint, what)
+			return "map[string]" + elem, err
+		}
+		return b.rawExtension(), nil
+	case p.Type == "string", p.Type == "number":
+		// controller-gen refuses floats, and recommends a string instead.
+		return "string", nil
+	case p.Type == "integer":
+		if p.Format == "int32" {
+			return "int32", nil
+		}
+		return "int64", nil
+	case p.Type == "boolean":
+		return "bool", nil
+	default:
+		return b.rawExtension(), nil
+	}
+}
+
+// structType returns the name of the type for object p, building it the first
+// time the object is seen on a side.
+func (b *builder) structType(key string, p *Schema, sd side, hint, what string) (string, error) {
+	if name, ok := b.named[key]; ok {
+		return name, nil
+	}
+	if b.visiting[key] {
+		return "", fmt.Errorf("%s contains itself, which a CRD cannot describe", what)
+	}
+	b.visiting[key] = true
+	defer delete(b.visiting, key)
+
+	name := unique(hint+sd.String(), b.taken)
+	at := len(b.structs)
+	b.structs = append(b.structs, Struct{})
+	fields, err := b.fields(p, sd, false, hint)
+	if err != nil {
+		return "", err
+	}
+	adjective := "configurable"
+	if sd == observation {
+		adjective = "observable"
+	}
+	doc := append([]string{fmt.Sprintf("%s are the %s fields of %s.", name, adjective, what)}, lines(p.Description)...)
+	b.structs[at] = Struct{Name: name, Doc: doc, Fields: fields}
+	b.named[key] = name
+	return name, nil
+}
+
+func (b *builder) rawExtension() string {
+	b.raw = true
+	return "runtime.RawExtension"
+}
+
+// unique returns name, or name with a number appended when it is in used, and
+// marks what it returns used.
+func unique(name string, used map[string]bool) string {
+	out := name
+	for i := 2; used[out]; i++ {
+		out = name + strconv.Itoa(i)
+	}
+	used[out] = true
+	return out
+}
+
+// lines splits a description into the lines of a comment.
+func lines(description string) []string {
+	description = strings.TrimSpace(description)
+	if description == "" {
+		return nil
+	}
+	out := strings.Split(description, "\n")
+	for i, line := range out {
+		out[i] = strings.TrimRight(line, " \t\r")
+	}
+	return out
+}
+
+// formats are the string formats the API server validates; others would only
+// be carried into the CRD, so they are left out.
+var formats = map[string]bool{
+	"byte": true, "cidr": true, "date": true, "date-time": true, "duration": true, "email": true,
+	"hostname": true, "ipv4": true, "ipv6": true, "mac": true, "password": true, "uri": true, "uuid": true,
+}
+
+// markers returns the kubebuilder markers for the validation p asks for.
+func markers(p *Schema, required bool) []string {
+	var out []string
+	if required {
+		out = append(out, "+kubebuilder:validation:Required")
+	} else {
+		out = append(out, "+optional")
+	}
+	if p.immutable {
+		out = append(out, `+kubebuilder:validation:XValidation:rule="self == oldSelf",message="Value is immutable"`)
+	}
+	if p.Type == "number" {
+		// Values of a string field holding a number cannot be bounded by the API server.
+		return append(out, "+kubebuilder:validation:Pattern=`^-?[0-9]+(\\.[0-9]+)?([eE][-+]?[0-9]+)?$`")
+	}
+	if len(p.Enum) > 0 {
+		values := make([]string, 0, len(p.Enum))
+		for _, v := range p.Enum {
+			if s, ok := v.(string); ok {
+				values = append(values, strconv.Quote(s))
+			} else {
+				values = append(values, fmt.Sprint(v))
+			}
+		}
+		out = append(out, "+kubebuilder:validation:Enum="+strings.Join(values, ";"))
+	}
+	bound := func(name string, v *float64) {
+		if v != nil {
+			out = append(out, fmt.Sprintf("+kubebuilder:validation:%s=%s", name, strconv.FormatFloat(*v, 'f', -1, 64)))
+		}
+	}
+	count := func(name string, v *int64) {
+		if v != nil {
+			out = append(out, fmt.Sprintf("+kubebuilder:validation:%s=%d", name, *v))
+		}
+	}
+	switch p.Type {
+	case "integer":
+		bound("Minimum", p.Minimum)
+		bound("Maximum", p.Maximum)
+	case "string":
+		count("MinLength", p.MinLength)
+		count("MaxLength", p.MaxLength)
+		if p.Pattern != "" {
+			pattern := "`" + p.Pattern + "`"
+			if strings.Contains(p.Pattern, "`") {
+				pattern = strconv.Quote(p.Pattern)
+			}
+			out = append(out, "+kubebuilder:validation:Pattern="+pattern)
+		}
+		if formats[p.Format] {
+			out = append(out, "+kubebuilder:validation:Format="+p.Format)
+		}
+	case "array":
+		count("MinItems", p.MinItems)
+		count("MaxItems", p.MaxItems)
+	}
+	return out
+}
@@ -0,0 +1,278 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const spec = `openapi: 3.0.3
+components:
+  schemas:
+    Tag:
+      type: object
+      required: [key]
+      properties:
+        key: {type: string, minLength: 1}
+        value: {type: string}
+    Bucket:
+      description: A storage bucket.
+      type: object
+      required: [name, region]
+      properties:
+        id: {type: string, readOnly: true, description: Unique identifier.}
+        name: {type: string, pattern: '^[a-z][a-z0-9-]{2,62}$', description: The bucket name.}
+        region: {type: string, enum: [us-east, eu-west]}
+        sizeGb: {type: integer, minimum: 10}
+        ratio: {type: number}
+        tags: {type: array, items: {$ref: '#/components/schemas/Tag'}}
+        labels: {type: object, additionalProperties: {type: string}}
+        encryption:
+          type: object
+          properties:
+            algorithm: {type: string, example: AES256}
+            keyId: {type: string, readOnly: true}
+        owner: {$ref: '#/components/schemas/Tag', readOnly: true}
+        extra: {}
+        secret: {type: string, writeOnly: true}
+    Node:
+      type: object
+      properties:
+        children: {type: array, items: {$ref: '#/components/schemas/Node'}}
+`
+
+func load(t *testing.T, content string) *Document {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "spec.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func field(fields []Field, json string) *Field {
+	for i := range fields {
+		if fields[i].JSONName == json {
+			return &fields[i]
+		}
+	}
+	return nil
+}
+
+func TestBuild(t *testing.T) {
+	m, err := Build(load(t, spec), "#/components/schemas/Bucket", "Bucket")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var params, observed []string
+	for _, f := range m.Parameters {
+		params = append(params, f.Name+" "+f.Type)
+	}
+	for _, f := range m.Observation {
+		observed = append(observed, f.Name+" "+f.Type)
+	}
+	wantParams := []string{
+		"Encryption *BucketEncryptionParameters", "Extra *runtime.RawExtension", "Labels map[string]string",
+		"Name string", "Ratio *string", "Region string", "Secret *string", "SizeGb *int64",
+		"Tags []BucketTagParameters",
+	}
+	if !slices.Equal(params, wantParams) {
+		t.Errorf("Parameters = %v, want %v", params, wantParams)
+	}
+	if want := []string{"ID *string", "Owner *BucketTagObservation"}; !slices.Equal(observed, want) {
+		t.Errorf("Observation = %v, want %v", observed, want)
+	}
+	if !m.RawExtension {
+		t.Error("RawExtension is not set for the untyped property")
+	}
+
+	name := field(m.Parameters, "name")
+	if name.Tag() != `json:"name"` || !slices.Equal(name.Comments, []string{
+		"The bucket name.", "+kubebuilder:validation:Required", "+kubebuilder:validation:Pattern=`^[a-z][a-z0-9-]{2,62}$`",
+	}) {
+		t.Errorf("name = %+v, %s", name, name.Tag())
+	}
+	if region := field(m.Parameters, "region"); !slices.Contains(region.Comments, `+kubebuilder:validation:Enum="us-east";"eu-west"`) {
+		t.Errorf("region comments = %v", region.Comments)
+	}
+	if size := field(m.Parameters, "sizeGb"); size.Tag() != `json:"sizeGb,omitempty"` ||
+		!slices.Contains(size.Comments, "+kubebuilder:validation:Minimum=10") {
+		t.Errorf("sizeGb = %+v, %s", size, size.Tag())
+	}
+
+	var structs []string
+	for _, s := range m.Structs {
+		structs = append(structs, s.Name)
+	}
+	if want := []string{"BucketEncryptionParameters", "BucketTagParameters", "BucketTagObservation"}; !slices.Equal(structs, want) {
+		t.Errorf("Structs = %v, want %v", structs, want)
+	}
+	if encryption := m.Structs[0]; len(encryption.Fields) != 1 || encryption.Fields[0].Name != "Algorithm" {
+		t.Errorf("%s has %+v, want only the writable algorithm", encryption.Name, encryption.Fields)
+	}
+
+	sample := m.ForProviderYAML(2)
+	for _, want := range []string{
+		"  forProvider:\n", "    name: example\n", "    region: us-east\n", "    sizeGb: 10\n", `    ratio: "1"` + "\n",
+		"    - key: example\n", "      algorithm: AES256\n",
+	} {
+		if !strings.Contains(sample, want) {
+			t.Errorf("sample does not contain %q:\n%s", want, sample)
+		}
+	}
+	if strings.Contains(sample, "keyId") || strings.Contains(sample, "owner") {
+		t.Errorf("sample has read-only properties:\n%s", sample)
+	}
+
+	for ref, wantErr := range map[string]string{
+		"Node":                       "contains itself",
+		"#/components/schemas/Nope":  "no schema",
+		"other.yaml#/components/Tag": "not supported",
+	} {
+		if _, err := Build(load(t, spec), ref, "X"); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("Build(%s) error = %v, want %q", ref, err, wantErr)
+		}
+	}
+}
+
+func TestLoadRejectsSwagger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "swagger.json")
+	_ = os.WriteFile(path, []byte(`{"swagger": "2.0", "definitions": {}}`), 0o644)
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "Swagger 2.0") {
+		t.Errorf("Load() error = %v, want Swagger refused", err)
+	}
+}
+
+func TestGoName(t *testing.T) {
+	for in, want := range map[string]string{
+		"name":          "Name",
+		"storage_class": "StorageClass",
+		"key-id":        "KeyID",
+		"instanceId":    "InstanceID",
+		"HTTPEndpoint":  "HTTPEndpoint",
+		"@odata.type":   "OdataType",
+		"2fa":           "X2fa",
+	} {
+		if got := goName(in); got != want {
+			t.Errorf("goName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestDeclare(t *testing.T) {
+	m, err := Declare("Bucket",
+		[]string{"region:string,required,immutable", "sizeGb:int", "tags:map[string]string", "zones:[]string"},
+		[]string{"arn:string", "ready:bool"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var params []string
+	for _, f := range m.Parameters {
+		params = append(params, f.Name+" "+f.Type+" "+f.Tag())
+	}
+	if want := []string{
+		`Region string json:"region"`, `SizeGb *int64 json:"sizeGb,omitempty"`,
+		`Tags map[string]string json:"tags,omitempty"`, `Zones []string json:"zones,omitempty"`,
+	}; !slices.Equal(params, want) {
+		t.Errorf("Parameters = %v, want %v", params, want)
+	}
+	if region := m.Parameters[0]; !slices.Contains(region.Comments,
+		`+kubebuilder:validation:XValidation:rule="self == oldSelf",message="Value is immutable"`) {
+		t.Errorf("region comments = %v, want the immutability rule", region.Comments)
+	}
+	if arn := m.Observation[0]; arn.Name != "ARN" || arn.Type != "*string" || len(m.Observation) != 2 {
+		t.Errorf("Observation = %+v", m.Observation)
+	}
+	if sample := m.ForProviderYAML(0); !strings.Contains(sample, "  region: example\n") ||
+		!strings.Contains(sample, "  - example") {
+		t.Errorf("sample:\n%s", sample)
+	}
+
+	for _, tc := range []struct {
+		params, observe []string
+		wantErr         string
+	}{
+		{params: []string{"region"}, wantErr: "not name:type"},
+		{params: []string{"Region:string"}, wantErr: "lowerCamelCase"},
+		{params: []string{"region:float"}, wantErr: "unknown type"},
+		{params: []string{"region:string,secret"}, wantErr: "unknown option"},
+		{params: []string{"region:string", "region:int"}, wantErr: "declared twice"},
+		{observe: []string{"arn:string,required"}, wantErr: "take no options"},
+	} {
+		if _, err := Declare("Bucket", tc.params, tc.observe); err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("Declare(%v, %v) error = %v, want %q", tc.params, tc.observe, err, tc.wantErr)
+		}
+	}
+}
+
+func TestReference(t *testing.T) {
+	for decl, want := range map[string]Reference{
+		"networkId=Network":          {Name: "networkId", Kind: "Network"},
+		"networkId=Network.network":  {Name: "networkId", Kind: "Network", Group: "network"},
+		"keyArn=Key.kms.example.com": {Name: "keyArn", Kind: "Key", Group: "kms.example.com"},
+	} {
+		if got, err := ParseReference(decl); err != nil || got != want {
+			t.Errorf("ParseReference(%q) = %+v, %v; want %+v", decl, got, err, want)
+		}
+	}
+	for _, decl := range []string{"networkId", "NetworkId=Network", "networkId=network", "networkId=Network.Net_work"} {
+		if _, err := ParseReference(decl); err == nil {
+			t.Errorf("ParseReference(%q) succeeded", decl)
+		}
+	}
+
+	m, err := Declare("Subnet", []string{"cidrBlock:string,required"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, _ := ParseReference("networkId=Network")
+	if err := m.AddReference(ref, "Network", false); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range m.Parameters {
+		got = append(got, f.Name+" "+f.Type)
+	}
+	if want := []string{
+		"CIDRBlock string", "NetworkID *string", "NetworkIDRef *xpv2.Reference", "NetworkIDSelector *xpv2.Selector",
+	}; !slices.Equal(got, want) {
+		t.Errorf("Parameters = %q, want %q", got, want)
+	}
+	if id := field(m.Parameters, "networkId"); !slices.Contains(id.Comments, "+crossplane:generate:reference:type=Network") {
+		t.Errorf("networkId has no reference marker: %q", id.Comments)
+	}
+	if err := m.AddReference(ref, "Network", false); err == nil {
+		t.Error("adding a reference twice succeeded")
+	}
+
+	// Only the example references the Network; the tests' manifests do not.
+	if sample := m.ForProviderYAML(0); strings.Contains(sample, "networkIdRef") {
+		t.Errorf("sample has the reference:\n%s", sample)
+	}
+	if want := "forProvider:\n  cidrBlock: example\n  networkIdRef:\n    name: example"; m.ExampleForProviderYAML(0) != want {
+		t.Errorf("example:\n%s\nwant:\n%s", m.ExampleForProviderYAML(0), want)
+	}
+}
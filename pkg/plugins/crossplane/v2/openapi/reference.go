@@ -0,0 +1,117 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openapi
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	referencedKind  = regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`)
+	referencedGroup = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)*$`)
+)
+
+// Reference is a Parameters field declared as name=Kind[.group]: the ID of
+// another managed resource, which Crossplane sets from a reference to it or a
+// selector of it before the kind's controller sees the resource.
+type Reference struct {
+	// Name is the ID field's JSON name.
+	Name string
+	Kind string
+	// Group is the referenced kind's group as declared, or empty for the
+	// referencing kind's own.
+	Group string
+}
+
+// ParseReference parses one name=Kind[.group] declaration.
+func ParseReference(decl string) (Reference, error) {
+	name, target, ok := strings.Cut(decl, "=")
+	if !ok {
+		return Reference{}, fmt.Errorf("reference %q is not name=Kind[.group]", decl)
+	}
+	if !declaredName.MatchString(name) {
+		return Reference{}, fmt.Errorf("reference %q: the name must be lowerCamelCase, such as networkId", decl)
+	}
+	kind, group, _ := strings.Cut(target, ".")
+	if !referencedKind.MatchString(kind) {
+		return Reference{}, fmt.Errorf("reference %q: %q is not a kind, such as Network", decl, kind)
+	}
+	if group != "" && !referencedGroup.MatchString(group) {
+		return Reference{}, fmt.Errorf("reference %q: %q is not an API group", decl, group)
+	}
+	return Reference{Name: name, Kind: kind, Group: group}, nil
+}
+
+// Fields returns the ID field of r and the Ref and Selector fields that set
+// it. typ is the referenced type as angryjet's reference marker takes it: the
+// kind alone when it is in the referencing kind's package, or else qualified
+// by its package's import path. A namespaced kind's references are namespaced,
+// and default to its own namespace.
+func (r Reference) Fields(typ string, namespaced bool) []Field {
+	id := goName(r.Name)
+	ref, selector := "xpv2.Reference", "xpv2.Selector"
+	if namespaced {
+		ref, selector = "xpv2.NamespacedReference", "xpv2.NamespacedSelector"
+	}
+	return []Field{
+		{
+			Name: id, JSONName: r.Name, Type: "*string",
+			Comments: []string{
+				fmt.Sprintf("%s is the ID of a %s. Set it, or have it resolved", id, r.Kind),
+				fmt.Sprintf("from %sRef or %sSelector.", id, id),
+				"+crossplane:generate:reference:type=" + typ,
+				"+optional",
+			},
+		},
+		{
+			Name: id + "Ref", JSONName: r.Name + "Ref", Type: "*" + ref,
+			Comments: []string{
+				fmt.Sprintf("%sRef references the %s to set %s from.", id, r.Kind, id),
+				"+optional",
+			},
+		},
+		{
+			Name: id + "Selector", JSONName: r.Name + "Selector", Type: "*" + selector,
+			Comments: []string{
+				fmt.Sprintf("%sSelector selects the %s to set %sRef to.", id, r.Kind, id),
+				"+optional",
+			},
+		},
+	}
+}
+
+// AddReference adds the fields of r to the model's Parameters, and a
+// reference to the object named example to its example; see Fields for typ
+// and namespaced.
+func (m *Model) AddReference(r Reference, typ string, namespaced bool) error {
+	fields := r.Fields(typ, namespaced)
+	for _, f := range m.Parameters {
+		for _, add := range fields {
+			if f.Name == add.Name || f.JSONName == add.JSONName {
+				return fmt.Errorf("reference %s: the Parameters already have a field %s", r.Name, f.JSONName)
+			}
+		}
+	}
+	m.Parameters = append(m.Parameters, fields...)
+	if m.refs == nil {
+		m.refs = map[string]any{}
+	}
+	m.refs[r.Name+"Ref"] = map[string]any{"name": "example"}
+	return nil
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openapi
+
+import (
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// formatSamples are valid values of the string formats a sample may need.
+var formatSamples = map[string]string{
+	"byte":      "ZXhhbXBsZQ==",
+	"cidr":      "10.0.0.0/16",
+	"date":      "2025-01-01",
+	"date-time": "2025-01-01T00:00:00Z",
+	"duration":  "1h",
+	"email":     "user@example.com",
+	"hostname":  "example.com",
+	"ipv4":      "192.0.2.1",
+	"ipv6":      "2001:db8::1",
+	"mac":       "00:00:5e:00:53:01",
+	"uri":       "https://example.com",
+	"url":       "https://example.com",
+	"uuid":      "123e4567-e89b-12d3-a456-426614174000",
+}
+
+// stringCandidates are tried in turn against a pattern when the schema offers
+// no example.
+var stringCandidates = []string{"example", "Example", "EXAMPLE", "example-1", "1", "a", "A"}
+
+// sampleObject returns a sample of object s's writable properties: the
+// required ones, and every optional one a valid value can be found for.
+func (b *builder) sampleObject(s *Schema) map[string]any {
+	out := map[string]any{}
+	for name, raw := range s.Properties {
+		p, err := b.resolve(raw)
+		if err != nil || p.ReadOnly {
+			continue
+		}
+		required := slices.Contains(s.Required, name)
+		if v, ok := b.sample(p, required); ok || required {
+			out[name] = v
+		}
+	}
+	return out
+}
+
+// sample returns a value for p, preferring the schema's own example, default
+// or first enum value. ok is false when no value is known to be valid; for a
+// required property it is still the best guess.
+func (b *builder) sample(p *Schema, required bool) (v any, ok bool) {
+	for _, given := range []any{p.Example, p.Default} {
+		if given != nil {
+			return asDeclared(p, given), true
+		}
+	}
+	if len(p.Enum) > 0 {
+		return asDeclared(p, p.Enum[0]), true
+	}
+
+	switch {
+	case len(p.OneOf) > 0 || len(p.AnyOf) > 0:
+		return map[string]any{}, required
+	case p.Type == "array" || p.Type == "" && p.Items != nil:
+		if p.Items == nil {
+			return []any{}, true
+		}
+		items, err := b.resolve(p.Items)
+		if err != nil {
+			return nil, false
+		}
+		item, ok := b.sample(items, true)
+		n := 1
+		if p.MinItems != nil {
+			n = max(n, int(*p.MinItems))
+		}
+		if p.MaxItems != nil {
+			n = min(n, int(*p.MaxItems))
+		}
+		list := make([]any, n)
+		for i := range list {
+			list[i] = item
+		}
+		return list, ok || n == 0
+	case p.Type == "object" || p.Type == "" && len(p.Properties) > 0:
+		if len(p.Properties) > 0 {
+			return b.sampleObject(p), true
+		}
+		if ap := p.AdditionalProperties; ap != nil && ap.Schema != nil && !reflect.ValueOf(*ap.Schema).IsZero() {
+			values, err := b.resolve(ap.Schema)
+			if err != nil {
+				return nil, false
+			}
+			value, ok := b.sample(values, true)
+			return map[string]any{"key": value}, ok
+		}
+		return map[string]any{}, required
+	case p.Type == "string":
+		return sampleString(p)
+	case p.Type == "integer":
+		return int64(sampleNumber(p, true)), true
+	case p.Type == "number":
+		return strconv.FormatFloat(sampleNumber(p, false), 'f', -1, 64), true
+	case p.Type == "boolean":
+		return true, true
+	default:
+		return map[string]any{}, required
+	}
+}
+
+// asDeclared returns v as the Go type holds it: a number the schema declares
+// as such is a string.
+func asDeclared(p *Schema, v any) any {
+	if f, ok := v.(float64); ok && p.Type == "number" {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return v
+}
+
+// sampleString returns a string in p's format that fits its length bounds and
+// matches its pattern; ok is false when no candidate does.
+func sampleString(p *Schema) (string, bool) {
+	candidates := stringCandidates
+	if s, ok := formatSamples[p.Format]; ok {
+		candidates = append([]string{s}, candidates...)
+	}
+	var pattern *regexp.Regexp
+	if p.Pattern != "" {
+		// A pattern Go cannot compile is not checked; ECMA syntax mostly is Go's.
+		pattern, _ = regexp.Compile(p.Pattern)
+	}
+	fit := func(s string) string {
+		if p.MinLength != nil && int64(len(s)) < *p.MinLength {
+			s += strings.Repeat("x", int(*p.MinLength)-len(s))
+		}
+		if p.MaxLength != nil && int64(len(s)) > *p.MaxLength {
+			s = s[:*p.MaxLength]
+		}
+		return s
+	}
+	for _, c := range candidates {
+		if s := fit(c); pattern == nil || pattern.MatchString(s) {
+			return s, true
+		}
+	}
+	return fit(candidates[0]), false
+}
+
+// sampleNumber returns 1, moved into p's bounds; a whole number for integers.
+func sampleNumber(p *Schema, integer bool) float64 {
+	v := 1.0
+	if p.Minimum != nil && v < *p.Minimum {
+		v = *p.Minimum
+		if integer {
+			v = math.Ceil(v)
+		}
+	}
+	if p.Maximum != nil && v > *p.Maximum {
+		v = *p.Maximum
+		if integer {
+			v = math.Floor(v)
+		}
+	}
+	return v
+}
@@ -3,10 +3,11 @@ package v2
 import (
 	"sigs.k8s.io/kubebuilder/v4/pkg/config"
 	"sigs.k8s.io/kubebuilder/v4/pkg/plugin"
-	"sigs.k8s.io/kubebuilder/v4/pkg/plugins/golang"
+
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/core"
 )
 
-const pluginName = "crossplane." + golang.DefaultNameQualifier
+const pluginName = core.PluginKey
 
 var (
 	pluginVersion            = plugin.Version{Number: 2}
@@ -25,9 +26,11 @@ func (p Plugin) GetInitSubcommand() plugin.InitSubcommand { return &initSubcomma
 
 func (p Plugin) GetCreateAPISubcommand() plugin.CreateAPISubcommand { return &createAPISubcommand{} }
 
-func (p Plugin) GetCreateWebhookSubcommand() plugin.CreateWebhookSubcommand { return nil }
+func (p Plugin) GetCreateWebhookSubcommand() plugin.CreateWebhookSubcommand {
+	return &createWebhookSubcommand{}
+}
 
-func (p Plugin) GetEditSubcommand() plugin.EditSubcommand { return nil }
+func (p Plugin) GetEditSubcommand() plugin.EditSubcommand { return &editSubcommand{} }
 
 func (p Plugin) DeprecationWarning() string { return "" }
 
@@ -17,9 +17,13 @@ limitations under the License.
 package v2
 
 import (
+	"path/filepath"
 	"testing"
 
+	cfgv3 "sigs.k8s.io/kubebuilder/v4/pkg/config/v3"
 	"sigs.k8s.io/kubebuilder/v4/pkg/plugin"
+
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/core"
 )
 
 func TestPlugin_Interface(_ *testing.T) {
@@ -88,16 +92,16 @@ func TestPlugin_Subcommands(t *testing.T) {
 		t.Error("Plugin should provide create api subcommand")
 	}
 
-	// Should not provide webhook subcommand (Crossplane doesn't use them typically)
+	// Should provide webhook subcommand
 	webhookCmd := p.GetCreateWebhookSubcommand()
-	if webhookCmd != nil {
-		t.Error("Plugin should not provide webhook subcommand")
+	if webhookCmd == nil {
+		t.Error("Plugin should provide create webhook subcommand")
 	}
 
-	// Should not provide edit subcommand
+	// Should provide edit subcommand
 	editCmd := p.GetEditSubcommand()
-	if editCmd != nil {
-		t.Error("Plugin should not provide edit subcommand")
+	if editCmd == nil {
+		t.Error("Plugin should provide edit subcommand")
 	}
 }
 
@@ -162,6 +166,48 @@ func TestPluginConfig_GenerateDefaultRepo(t *testing.T) {
 	}
 }
 
+// TestPluginConfig_Project checks that commands on an existing project run with
+// the settings PROJECT recorded at init, over this machine's configuration.
+func TestPluginConfig_Project(t *testing.T) {
+	t.Setenv("XP_PROVIDER_GEN_CONFIG", filepath.Join(t.TempDir(), "none.yaml"))
+	t.Setenv("XP_PROVIDER_GEN_GIT_AUTHOR", "Someone Else")
+	cfg := cfgv3.New()
+	_ = cfg.SetRepository("github.com/acme/provider-test")
+	_ = cfg.SetDomain("acme.io")
+	recorded := core.GitConfig{Author: "Acme Bot", Email: "bot@acme.io"}
+	settings := core.ProjectSettings{Generator: core.GeneratorSettings{Git: recorded}}
+	if err := core.SaveProjectSettings(cfg, settings); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := loadPluginConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Git.Author != recorded.Author || c.Git.Email != recorded.Email {
+		t.Errorf("loadPluginConfig: git identity = %s <%s>, want PROJECT's", c.Git.Author, c.Git.Email)
+	}
+
+	createAPI := &createAPISubcommand{}
+	createWebhook := &createWebhookSubcommand{}
+	edit := &editSubcommand{repo: "github.com/acme/provider-moved"}
+	for name, sub := range map[string]struct {
+		inject func() error
+		config func() *PluginConfig
+	}{
+		"create api":     {func() error { return createAPI.InjectConfig(cfg) }, func() *PluginConfig { return createAPI.pluginConfig }},
+		"create webhook": {func() error { return createWebhook.InjectConfig(cfg) }, func() *PluginConfig { return createWebhook.pluginConfig }},
+		"edit":           {func() error { return edit.InjectConfig(cfg) }, func() *PluginConfig { return edit.pluginConfig }},
+	} {
+		if err := sub.inject(); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got := sub.config().Git.Author; got != recorded.Author {
+			t.Errorf("%s: pipeline author = %q, want PROJECT's %q", name, got, recorded.Author)
+		}
+	}
+}
+
 // Helper function.
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) &&
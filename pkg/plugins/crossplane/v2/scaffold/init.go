@@ -41,7 +41,7 @@ func (s *InitScaffolder) Scaffold(fs machinery.Filesystem) error {
 
 	scaffold := machinery.NewScaffold(fs,
 		machinery.WithConfig(s.config),
-		machinery.WithBoilerplate(engine.DefaultBoilerplate()),
+		machinery.WithBoilerplate(engine.ProjectBoilerplate(s.config)),
 	)
 
 	factory := engine.NewFactory(s.config)
@@ -38,6 +38,67 @@ type TemplateInfo struct {
 	Category TemplateCategory
 }
 
+// Conversion templates render for only some versions of a multi-version kind:
+// the hub marker for the storage version, the conversion functions for every
+// other version. The admission seam renders only for a kind with webhooks, and
+// the connection builder and its e2e check only for a kind with connection keys,
+// the import test only for a kind with an external-name strategy, the fake
+// API and its test only for a kind that talks to one, and the example
+// ProviderConfig in the config namespace only for a cluster-scoped kind.
+const (
+	hubTemplate             = "files/apis/GROUP/VERSION/KIND_hub.go.tmpl"
+	conversionTemplate      = "files/apis/GROUP/VERSION/KIND_conversion.go.tmpl"
+	webhookTemplate         = "files/internal/controller/KIND/webhook.go.tmpl"
+	connectionTemplate      = "files/internal/controller/KIND/zz_connection.go.tmpl"
+	connectionCheckTemplate = "files/test/e2e/KIND-connection.sh.tmpl"
+	importTemplate          = "files/test/behavior/KIND-import/chainsaw-test.yaml.tmpl"
+	fakeServerTemplate      = "files/internal/fake/KIND/server.go.tmpl"
+	fakeClientTemplate      = "files/internal/fake/KIND/client.go.tmpl"
+	fakeTestTemplate        = "files/test/behavior/KIND-api-errors/chainsaw-test.yaml.tmpl"
+	clusterConfigTemplate   = "files/examples/provider/KIND-config.yaml.tmpl"
+)
+
+// RendersFor reports whether an API template renders for options.Resource,
+// given its kind's hub (storage) version — empty for a kind with one version —
+// connection keys, external-name strategy and fake API.
+//
+// A template whose path has no VERSION belongs to the kind as a whole (its
+// controller, example and tests), so only the hub renders it: one controller
+// reconciles the storage version however many versions are served.
+func (info TemplateInfo) RendersFor(options *TemplateOptions) bool {
+	res, hubVersion := options.Resource, options.HubVersion
+	switch info.Path {
+	case hubTemplate:
+		return hubVersion != "" && res.Version == hubVersion
+	case conversionTemplate:
+		return hubVersion != "" && res.Version != hubVersion
+	case webhookTemplate:
+		if !res.HasDefaultingWebhook() && !res.HasValidationWebhook() {
+			return false
+		}
+	case connectionTemplate, connectionCheckTemplate:
+		if len(options.ConnectionKeys) == 0 {
+			return false
+		}
+	case importTemplate:
+		if options.ExternalName == "" {
+			return false
+		}
+	case fakeServerTemplate, fakeClientTemplate, fakeTestTemplate:
+		if !options.FakeAPI {
+			return false
+		}
+	case clusterConfigTemplate:
+		if res.API == nil || res.API.Namespaced {
+			return false
+		}
+	}
+	if !core.PathHasPattern(info.Path, []string{placeholderVersion}) {
+		return hubVersion == "" || res.Version == hubVersion
+	}
+	return true
+}
+
 // AnalyzeTemplatePath classifies one embedded template path.
 func AnalyzeTemplatePath(path string) TemplateInfo {
 	return TemplateInfo{Path: path, Category: determineCategory(path)}
@@ -16,11 +16,41 @@ limitations under the License.
 
 package engine
 
-// DefaultBoilerplate returns the standard Apache 2.0 license boilerplate
-// used for all generated Crossplane provider files.
-func DefaultBoilerplate() string {
-	return `/*
-Copyright 2025 The Crossplane Authors.
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/v4/pkg/config"
+
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/core"
+)
+
+// Boilerplate returns the header every generated Go file starts with: the
+// custom one recorded from --boilerplate-file, or else the standard notice
+// for the license.
+func Boilerplate(l core.License) string {
+	l = l.WithDefaults()
+	if l.Boilerplate != "" {
+		return strings.TrimRight(l.Boilerplate, "\n")
+	}
+	switch l.ID {
+	case core.LicenseMIT:
+		return fmt.Sprintf(`/*
+Copyright %d %s.
+
+Use of this source code is governed by the MIT License
+that can be found in the LICENSE file.
+*/`, l.Year, l.Owner)
+	case core.LicenseProprietary:
+		return fmt.Sprintf(`/*
+Copyright %d %s. All rights reserved.
+
+This file is proprietary and confidential. See the LICENSE file
+for the terms under which it may be used.
+*/`, l.Year, l.Owner)
+	default:
+		return fmt.Sprintf(`/*
+Copyright %d %s.
 
 Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
@@ -33,5 +63,14 @@ distributed under the License is distributed on an "AS IS" BASIS,
 WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
 See the License for the specific language governing permissions and
 limitations under the License.
-*/`
+*/`, l.Year, l.Owner)
+	}
+}
+
+// ProjectBoilerplate returns the Boilerplate for the license recorded in
+// cfg's PROJECT, for the scaffolds to inject into generators. Settings that do
+// not decode get the default here; every template's Configure reports them.
+func ProjectBoilerplate(cfg config.Config) string {
+	settings, _ := core.LoadProjectSettings(cfg)
+	return Boilerplate(settings.License)
 }
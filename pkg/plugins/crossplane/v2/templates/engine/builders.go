@@ -23,6 +23,7 @@ import (
 	"sigs.k8s.io/kubebuilder/v4/pkg/config"
 
 	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/core"
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/openapi"
 )
 
 // Path placeholder tokens: uppercase segments in template paths replaced at
@@ -73,8 +74,9 @@ func replacementsFor(cfg config.Config, options *TemplateOptions) map[string]str
 	return replacements
 }
 
-// configureProduct applies the project config, resource and force flag, then
-// loads the template body.
+// configureProduct applies the project config, resource, hub version, schema,
+// connection keys, external-name strategy, fake API and force flag, then loads
+// the template body.
 func configureProduct(product *GenericTemplateProduct, cfg config.Config, options *TemplateOptions) error {
 	if err := product.Configure(cfg); err != nil {
 		return fmt.Errorf("failed to configure template: %w", err)
@@ -84,6 +86,15 @@ func configureProduct(product *GenericTemplateProduct, cfg config.Config, option
 			return fmt.Errorf("failed to set resource: %w", err)
 		}
 	}
+	product.HubVersion = options.HubVersion
+	product.Schema = options.Schema
+	product.ConnectionKeys = nil
+	for _, key := range options.ConnectionKeys {
+		product.ConnectionKeys = append(product.ConnectionKeys, ConnectionKey{Key: key, Name: openapi.GoName(key)})
+	}
+	strategy, text, _ := strings.Cut(options.ExternalName, ":")
+	product.ExternalName = ExternalName{Strategy: strategy, Template: text}
+	product.FakeAPI = options.FakeAPI
 	if options.Force {
 		// Without --force the zero value (machinery.SkipFile) applies, which is
 		// what a second `create api` in an existing group/version needs: the
@@ -23,16 +23,19 @@ import (
 	"sigs.k8s.io/kubebuilder/v4/pkg/config"
 
 	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/core"
-	"github.com/cychiang/xp-provider-gen/pkg/templates"
 )
 
-// CrossplaneTemplateFactory holds the templates discovered in the embedded FS,
-// split by when they render. The two lists are only ever iterated, so they are
-// slices: nothing looks a template up by name.
+// CrossplaneTemplateFactory holds the templates discovered in the embedded FS
+// and the provider's overlay, split by when they render. The two lists are only
+// ever iterated, so they are slices: nothing looks a template up by name.
 type CrossplaneTemplateFactory struct {
 	config        config.Config
 	initTemplates []TemplateInfo
 	apiTemplates  []TemplateInfo
+
+	// err is why discovery failed, returned by every Get. Only the overlay,
+	// read from disk, can fail it.
+	err error
 }
 
 func NewFactory(cfg config.Config) TemplateFactory {
@@ -42,7 +45,7 @@ func NewFactory(cfg config.Config) TemplateFactory {
 }
 
 func (f *CrossplaneTemplateFactory) discoverTemplates() {
-	err := fs.WalkDir(templates.TemplateFS, "files", func(path string, d fs.DirEntry, err error) error {
+	err := fs.WalkDir(templateFS(), overlayRoot, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
@@ -59,18 +62,52 @@ func (f *CrossplaneTemplateFactory) discoverTemplates() {
 		return nil
 	})
 	if err != nil {
-		// The template FS is embedded at compile time, so a discovery failure
-		// is a build defect — fail loudly rather than scaffold incompletely.
-		panic(fmt.Errorf("discovering templates: %w", err))
+		// Never scaffold from a partial template set.
+		f.err = fmt.Errorf("discovering templates (is %s readable?): %w", OverlayDir, err)
 	}
 }
 
 func (f *CrossplaneTemplateFactory) GetInitTemplates(opts ...Option) ([]TemplateProduct, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
 	return f.build(f.initTemplates, opts)
 }
 
+// GetAPITemplates returns the API templates that render for the resource given
+// by WithResource, as decided by TemplateInfo.RendersFor. The connection keys,
+// external-name strategy and fake API of the resource's kind are read from
+// PROJECT.
 func (f *CrossplaneTemplateFactory) GetAPITemplates(opts ...Option) ([]TemplateProduct, error) {
-	return f.build(f.apiTemplates, opts)
+	if f.err != nil {
+		return nil, f.err
+	}
+	options := &TemplateOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	infos := f.apiTemplates
+	if options.Resource != nil {
+		if f.config != nil {
+			settings, err := core.LoadProjectSettings(f.config)
+			if err != nil {
+				return nil, err
+			}
+			res := *options.Resource
+			keys, strategy, fake := settings.ConnectionKeys(res), settings.ExternalName(res), settings.FakeAPI(res)
+			options.ConnectionKeys, options.ExternalName, options.FakeAPI = keys, strategy, fake
+			opts = append(opts, func(o *TemplateOptions) {
+				o.ConnectionKeys, o.ExternalName, o.FakeAPI = keys, strategy, fake
+			})
+		}
+		infos = nil
+		for _, info := range f.apiTemplates {
+			if info.RendersFor(options) {
+				infos = append(infos, info)
+			}
+		}
+	}
+	return f.build(infos, opts)
 }
 
 // build renders each discovered template into a product.
@@ -0,0 +1,76 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"testing"
+
+	cfgv3 "sigs.k8s.io/kubebuilder/v4/pkg/config/v3"
+	"sigs.k8s.io/kubebuilder/v4/pkg/model/resource"
+)
+
+func TestGetAPITemplates_VersionRoles(t *testing.T) {
+	cfg := cfgv3.New()
+	_ = cfg.SetRepository(testRepo)
+	_ = cfg.SetDomain("example.com")
+	factory := NewFactory(cfg)
+
+	const (
+		types      = "apis/storage/v1beta1/bucket_types.go"
+		hub        = "apis/storage/v1beta1/bucket_hub.go"
+		conversion = "apis/storage/v1beta1/bucket_conversion.go"
+		wiring     = "internal/controller/bucket/wiring.go"
+	)
+	cases := map[string]struct {
+		hubVersion string
+		want       map[string]bool
+	}{
+		"SoleVersion": {
+			hubVersion: "",
+			want:       map[string]bool{types: true, hub: false, conversion: false, wiring: true},
+		},
+		"Hub": {
+			hubVersion: "v1beta1",
+			want:       map[string]bool{types: true, hub: true, conversion: false, wiring: true},
+		},
+		"Spoke": {
+			hubVersion: "v1",
+			want:       map[string]bool{types: true, hub: false, conversion: true, wiring: false},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			res := &resource.Resource{
+				GVK: resource.GVK{Group: "storage", Version: "v1beta1", Kind: "Bucket", Domain: "example.com"},
+				API: &resource.API{CRDVersion: "v1", Namespaced: true},
+			}
+			products, err := factory.GetAPITemplates(WithResource(res), WithHubVersion(tc.hubVersion))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := map[string]bool{}
+			for _, p := range products {
+				got[p.GetPath()] = true
+			}
+			for path, want := range tc.want {
+				if got[path] != want {
+					t.Errorf("renders %s = %v, want %v", path, got[path], want)
+				}
+			}
+		})
+	}
+}
@@ -20,6 +20,8 @@ import (
 	"sigs.k8s.io/kubebuilder/v4/pkg/config"
 	"sigs.k8s.io/kubebuilder/v4/pkg/machinery"
 	"sigs.k8s.io/kubebuilder/v4/pkg/model/resource"
+
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/openapi"
 )
 
 // TemplateProduct is a template ready for machinery to render.
@@ -30,8 +32,8 @@ type TemplateProduct interface {
 	SetResource(res *resource.Resource) error
 }
 
-// TemplateFactory serves the templates discovered in the embedded FS, split by
-// when they render.
+// TemplateFactory serves the templates discovered in the embedded FS and the
+// provider's overlay, split by when they render.
 type TemplateFactory interface {
 	GetInitTemplates(opts ...Option) ([]TemplateProduct, error)
 	GetAPITemplates(opts ...Option) ([]TemplateProduct, error)
@@ -42,6 +44,26 @@ type Option func(*TemplateOptions)
 type TemplateOptions struct {
 	Force    bool
 	Resource *resource.Resource
+
+	// HubVersion is the storage version of Resource's kind when the kind is
+	// served in several versions, and empty when it has one.
+	HubVersion string
+
+	// Schema is the OpenAPI schema the resource's types are generated from,
+	// for `create api --from-openapi`; nil renders the placeholder fields.
+	Schema *openapi.Model
+
+	// ConnectionKeys are the keys the resource's kind publishes to its
+	// connection secret, as recorded in PROJECT.
+	ConnectionKeys []string
+
+	// ExternalName is the external-name strategy of the resource's kind, as
+	// recorded in PROJECT; empty when it chose none.
+	ExternalName string
+
+	// FakeAPI is set when the resource's kind talks to a generated fake of
+	// its external API, as recorded in PROJECT.
+	FakeAPI bool
 }
 
 func WithForce(force bool) Option {
@@ -51,3 +73,16 @@ func WithForce(force bool) Option {
 func WithResource(resource *resource.Resource) Option {
 	return func(opts *TemplateOptions) { opts.Resource = resource }
 }
+
+// WithHubVersion names the storage version of the resource's kind, for a kind
+// served in several versions. It decides which API templates render: see
+// TemplateInfo.RendersFor.
+func WithHubVersion(version string) Option {
+	return func(opts *TemplateOptions) { opts.HubVersion = version }
+}
+
+// WithSchema generates the resource's Parameters and Observation, and the
+// sample in its manifests, from an OpenAPI schema.
+func WithSchema(schema *openapi.Model) Option {
+	return func(opts *TemplateOptions) { opts.Schema = schema }
+}
@@ -17,30 +17,28 @@ limitations under the License.
 package engine
 
 import (
-	"embed"
 	"fmt"
+	"io/fs"
 
 	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/core"
-	"github.com/cychiang/xp-provider-gen/pkg/templates"
 )
 
-var templateFS embed.FS = templates.TemplateFS
-
-// TemplateLoader loads templates from the embedded filesystem.
+// TemplateLoader loads templates from the embedded filesystem, with the
+// provider's overlay on top (see OverlayDir).
 type TemplateLoader struct {
-	fs embed.FS
+	fs fs.FS
 }
 
 // NewTemplateLoader creates a new template loader.
 func NewTemplateLoader() *TemplateLoader {
 	return &TemplateLoader{
-		fs: templateFS,
+		fs: templateFS(),
 	}
 }
 
 // LoadTemplate loads a template by its name/path.
 func (tl *TemplateLoader) LoadTemplate(templatePath string) (string, error) {
-	content, err := tl.fs.ReadFile(core.ConvertToFilesystemPath(templatePath))
+	content, err := fs.ReadFile(tl.fs, core.ConvertToFilesystemPath(templatePath))
 	if err != nil {
 		return "", fmt.Errorf("failed to load template %s: %w", templatePath, err)
 	}
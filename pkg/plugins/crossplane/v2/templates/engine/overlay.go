@@ -0,0 +1,121 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cychiang/xp-provider-gen/pkg/templates"
+)
+
+// OverlayDir is where a provider keeps its own templates, relative to its root.
+// It has the layout of the embedded files/ directory: a template there replaces
+// the embedded one at the same path, and any other is discovered and rendered
+// like the embedded ones. Ownership follows the overlay's body, so an override
+// that keeps the generated header stays tool-owned.
+const OverlayDir = ".xp-provider-gen/templates"
+
+// overlayRoot is the embedded directory the overlay is laid over.
+const overlayRoot = "files"
+
+// templateFS returns the templates the engine renders: the embedded set with
+// the overlay in the working directory — the provider root, where PROJECT is
+// read from too — on top. Without an overlay it is the embedded set.
+func templateFS() overlayFS {
+	return overlayFS{base: templates.TemplateFS, overlay: os.DirFS(OverlayDir)}
+}
+
+// overlayFS serves base with overlay laid over its files/ directory. Every
+// other path (the generator bodies) comes from base alone.
+type overlayFS struct {
+	base    fs.FS
+	overlay fs.FS
+}
+
+// overlayPath maps a base path under files/ to its path in the overlay.
+func overlayPath(name string) (string, bool) {
+	if name == overlayRoot {
+		return ".", true
+	}
+	rel, ok := strings.CutPrefix(name, overlayRoot+"/")
+	return rel, ok
+}
+
+// Open opens the overlay's copy of name if it has one, and base's otherwise.
+func (o overlayFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if rel, ok := overlayPath(name); ok {
+		f, err := o.overlay.Open(rel)
+		if err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return o.base.Open(name)
+}
+
+// overlays reports whether the overlay supplies name.
+func (o overlayFS) overlays(name string) bool {
+	rel, ok := overlayPath(name)
+	if !ok {
+		return false
+	}
+	_, err := fs.Stat(o.overlay, rel)
+	return err == nil
+}
+
+// ReadDir lists a directory of either FS, merged by name; an entry in the
+// overlay hides base's entry of the same name.
+func (o overlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	baseEntries, baseErr := fs.ReadDir(o.base, name)
+	if baseErr != nil && !errors.Is(baseErr, fs.ErrNotExist) {
+		return nil, baseErr
+	}
+	rel, ok := overlayPath(name)
+	if !ok {
+		return baseEntries, baseErr
+	}
+	overlayEntries, err := fs.ReadDir(o.overlay, rel)
+	if errors.Is(err, fs.ErrNotExist) {
+		return baseEntries, baseErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]fs.DirEntry, len(baseEntries)+len(overlayEntries))
+	for _, e := range baseEntries {
+		byName[e.Name()] = e
+	}
+	for _, e := range overlayEntries {
+		byName[e.Name()] = e
+	}
+	entries := make([]fs.DirEntry, 0, len(byName))
+	for _, e := range byName {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	cfgv3 "sigs.k8s.io/kubebuilder/v4/pkg/config/v3"
+	"sigs.k8s.io/kubebuilder/v4/pkg/model/resource"
+
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/core"
+)
+
+func TestOverlayShadowsAndAddsTemplates(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for path, body := range map[string]string{
+		// Replaces the embedded template.
+		"project/README.md.tmpl": "# {{ .ProviderName }}, the house way\n",
+		// Adds a per-kind file, discovered like an embedded one.
+		"internal/controller/KIND/metrics.go.tmpl": core.GeneratedHeader + "\n\npackage {{ lower .Resource.Kind }}\n",
+		// Not a template: ignored, as in the embedded set.
+		"internal/controller/KIND/NOTES.md": "house notes\n",
+	} {
+		path = filepath.Join(OverlayDir, path)
+		_ = os.MkdirAll(filepath.Dir(path), 0o755)
+		_ = os.WriteFile(path, []byte(body), 0o644)
+	}
+
+	cfg := cfgv3.New()
+	_ = cfg.SetRepository(testRepo)
+	_ = cfg.SetDomain("example.com")
+	factory := NewFactory(cfg)
+
+	products, err := factory.GetInitTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bodies := map[string]string{}
+	for _, p := range products {
+		bodies[p.GetPath()] = p.GetBody()
+	}
+	if !strings.Contains(bodies["README.md"], "the house way") {
+		t.Errorf("README.md renders %q, want the overlay's template", bodies["README.md"])
+	}
+	if _, ok := bodies["Makefile"]; !ok {
+		t.Error("the embedded templates the overlay does not replace are gone")
+	}
+
+	res := &resource.Resource{
+		GVK: resource.GVK{Group: "storage", Version: "v1beta1", Kind: "Bucket", Domain: "example.com"},
+		API: &resource.API{CRDVersion: "v1", Namespaced: true},
+	}
+	products, err = factory.GetAPITemplates(WithResource(res))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, p := range products {
+		paths = append(paths, p.GetPath())
+	}
+	for _, want := range []string{"internal/controller/bucket/metrics.go", "internal/controller/bucket/wiring.go"} {
+		if !slices.Contains(paths, want) {
+			t.Errorf("API templates are missing %s: %v", want, paths)
+		}
+	}
+	if slices.Contains(paths, "internal/controller/bucket/NOTES.md") {
+		t.Error("a non-template file in the overlay was rendered")
+	}
+
+	doc := NewOwnershipDocGenerator()
+	metrics := "internal/controller/<kind>/metrics.go"
+	if !slices.Contains(doc.ToolOwned, metrics) {
+		t.Errorf("tool-owned bucket is missing the overlay's %s: %v", metrics, doc.ToolOwned)
+	}
+	if want := []string{"README.md", metrics}; !slices.Equal(doc.Overlaid, want) {
+		t.Errorf("Overlaid = %v, want %v", doc.Overlaid, want)
+	}
+}
@@ -50,6 +50,14 @@ type OwnershipDocGenerator struct {
 
 	ToolOwned []string
 	UserOwned []string
+	Seams     []Seam
+
+	// Overlaid lists the outputs whose template comes from the provider's
+	// overlay (OverlayDir) instead of the generator.
+	Overlaid []string
+
+	// err is why the template walk failed, returned by SetTemplateDefaults.
+	err error
 }
 
 var _ machinery.Template = &OwnershipDocGenerator{}
@@ -59,28 +67,33 @@ var _ machinery.Template = &OwnershipDocGenerator{}
 // path and ownership are read from the generators themselves — OverwriteFile
 // means tool-owned, SkipFile means seeded once and then the user's.
 func NewOwnershipDocGenerator(siblings ...machinery.Template) *OwnershipDocGenerator {
-	g := &OwnershipDocGenerator{}
+	g := &OwnershipDocGenerator{Seams: Seams}
 
 	// Walk the template FS directly. Template base names are not unique
 	// (Makefile.tmpl exists twice), so any name-keyed map would drop a file.
 	//
 	// GenerateOutputPath strips the "project/" prefix and applies the path
 	// placeholders, so the doc lists paths that actually exist in a provider.
-	err := fs.WalkDir(templates.TemplateFS, "files", func(path string, d fs.DirEntry, err error) error {
+	tfs := templateFS()
+	err := fs.WalkDir(tfs, overlayRoot, func(path string, d fs.DirEntry, err error) error {
 		if err != nil || d.IsDir() || !core.IsTemplateFile(path) {
 			return err
 		}
-		body, readErr := templates.TemplateFS.ReadFile(path)
+		body, readErr := fs.ReadFile(tfs, path)
 		if readErr != nil {
 			return readErr
 		}
-		g.add(core.GenerateOutputPath(path, docPlaceholders), core.IsToolOwned(body))
+		output := core.GenerateOutputPath(path, docPlaceholders)
+		g.add(output, core.IsToolOwned(body))
+		if tfs.overlays(path) {
+			g.Overlaid = append(g.Overlaid, output)
+		}
 		return nil
 	})
 	if err != nil {
-		// The template FS is embedded at compile time, so a walk failure is a
-		// build defect — fail loudly rather than emit an incomplete doc.
-		panic(fmt.Errorf("enumerating embedded templates for the ownership doc: %w", err))
+		// Only the overlay, read from disk, can fail the walk; an incomplete
+		// doc would misstate the contract, so rendering it fails instead.
+		g.err = fmt.Errorf("enumerating templates for the ownership doc: %w", err)
 	}
 
 	g.add(ownershipDocPath, true)
@@ -93,6 +106,7 @@ func NewOwnershipDocGenerator(siblings ...machinery.Template) *OwnershipDocGener
 
 	sort.Strings(g.ToolOwned)
 	sort.Strings(g.UserOwned)
+	sort.Strings(g.Overlaid)
 	return g
 }
 
@@ -106,6 +120,9 @@ func (f *OwnershipDocGenerator) add(path string, toolOwned bool) {
 }
 
 func (f *OwnershipDocGenerator) SetTemplateDefaults() error {
+	if f.err != nil {
+		return f.err
+	}
 	f.Path = ownershipDocPath
 	f.IfExistsAction = machinery.OverwriteFile
 	f.TemplateBody = templates.GeneratorBody("ownership_doc.md.tmpl")
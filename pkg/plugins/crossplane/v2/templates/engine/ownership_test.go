@@ -38,28 +38,43 @@ import (
 var wantOwnership = map[string]bool{
 	"apis/doc.go":      true,
 	"apis/generate.go": true,
-	"apis/GROUP/VERSION/groupversion_info.go":     true,
-	"apis/GROUP/VERSION/KIND_types.go":            false,
-	"apis/v1alpha1/register.go":                   true,
-	"apis/v1alpha1/types.go":                      false,
-	"cluster/images/IMAGENAME/Dockerfile":         false,
-	"cluster/images/IMAGENAME/Makefile":           false,
-	"cluster/local/integration_tests.sh":          true,
-	"cmd/provider/main.go":                        true,
-	"examples/GROUP/KIND.yaml":                    false,
-	"examples/provider/config.yaml":               false,
-	"hack/boilerplate.go.txt":                     false,
-	"internal/controller/config/config.go":        true,
-	"internal/controller/KIND/external.go":        false,
-	"internal/controller/KIND/wiring.go":          true,
-	"internal/provider/connector.go":              true,
-	"internal/provider/client.go":                 false,
-	"internal/provider/options.go":                false,
-	"internal/version/version.go":                 true,
-	"test/setup.sh":                               false,
-	"test/README.md":                              false,
-	"test/e2e/KIND-lifecycle.yaml":                false,
-	"test/behavior/KIND-pause/chainsaw-test.yaml": false,
+	"apis/GROUP/VERSION/groupversion_info.go":          true,
+	"apis/GROUP/VERSION/KIND_types.go":                 false,
+	"apis/GROUP/VERSION/KIND_hub.go":                   true,
+	"apis/GROUP/VERSION/KIND_conversion.go":            false,
+	"apis/v1alpha1/register.go":                        true,
+	"apis/v1alpha1/types.go":                           false,
+	"cluster/images/IMAGENAME/Dockerfile":              false,
+	"cluster/images/IMAGENAME/Makefile":                false,
+	"cluster/local/integration_tests.sh":               true,
+	"cmd/provider/main.go":                             true,
+	"examples/GROUP/KIND.yaml":                         false,
+	"examples/provider/config.yaml":                    false,
+	"examples/provider/KIND-config.yaml":               false,
+	"hack/boilerplate.go.txt":                          false,
+	"internal/controller/config/config.go":             true,
+	"internal/controller/KIND/external.go":             false,
+	"internal/controller/KIND/external_test.go":        false,
+	"internal/controller/KIND/integration_test.go":     false,
+	"internal/controller/KIND/wiring.go":               true,
+	"internal/controller/KIND/webhook.go":              false,
+	"internal/controller/KIND/zz_compare.go":           true,
+	"internal/controller/KIND/zz_connection.go":        true,
+	"internal/fake/KIND/client.go":                     true,
+	"internal/fake/KIND/server.go":                     true,
+	"internal/compare/compare.go":                      true,
+	"internal/provider/connector.go":                   true,
+	"internal/provider/connector_test.go":              true,
+	"internal/provider/client.go":                      false,
+	"internal/provider/options.go":                     false,
+	"internal/version/version.go":                      true,
+	"test/setup.sh":                                    false,
+	"test/README.md":                                   false,
+	"test/e2e/KIND-connection.sh":                      true,
+	"test/e2e/KIND-lifecycle.yaml":                     false,
+	"test/behavior/KIND-api-errors/chainsaw-test.yaml": false,
+	"test/behavior/KIND-import/chainsaw-test.yaml":     false,
+	"test/behavior/KIND-pause/chainsaw-test.yaml":      false,
 	"AGENTS.md":               false,
 	"LICENSE":                 false,
 	"package/crossplane.yaml": false,
@@ -17,11 +17,15 @@ limitations under the License.
 package engine
 
 import (
+	"fmt"
+	"strings"
+
 	"sigs.k8s.io/kubebuilder/v4/pkg/config"
 	"sigs.k8s.io/kubebuilder/v4/pkg/machinery"
 	"sigs.k8s.io/kubebuilder/v4/pkg/model/resource"
 
 	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/core"
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/openapi"
 )
 
 // BaseTemplateProduct provides common functionality for all template products.
@@ -34,6 +38,55 @@ type BaseTemplateProduct struct {
 
 	ProviderName string
 	Force        bool
+
+	// ClusterProviderConfig mirrors the project setting: the provider also
+	// offers a cluster-scoped ClusterProviderConfig.
+	ClusterProviderConfig bool
+
+	// WebhookServer is set once the provider serves any webhook: conversion
+	// for a kind served in several versions, or admission added by
+	// `create webhook`.
+	WebhookServer bool
+
+	// HubVersion is the storage version of the resource's kind when it is
+	// served in several versions, and empty when it has one.
+	HubVersion string
+
+	// Schema is the model of the OpenAPI schema the resource's types come
+	// from (`create api --from-openapi`), and nil otherwise.
+	Schema *openapi.Model
+
+	// ConnectionKeys are the keys the resource's kind publishes to its
+	// connection secret (`create api --connection-key`), in declared order.
+	ConnectionKeys []ConnectionKey
+
+	// ExternalName is the external-name strategy of the resource's kind
+	// (`create api --external-name`); its zero value when it chose none.
+	ExternalName ExternalName
+
+	// FakeAPI is set when the resource's kind talks to the fake of its
+	// external API in internal/fake/<kind> (`create api --fake-api`).
+	FakeAPI bool
+
+	// RegistryOrgs are the package registries the provider publishes to, as
+	// recorded in PROJECT at `init`.
+	RegistryOrgs []string
+
+	// License is the provider's license as recorded in PROJECT, with the
+	// defaults filled in; the Go boilerplate is rendered from it.
+	License core.License
+}
+
+// ConnectionKey is a connection secret key and the Go name the kind's
+// Connection gives it.
+type ConnectionKey struct {
+	Key, Name string
+}
+
+// ExternalName is an external-name strategy: Strategy is name, generated,
+// identifier or template, and Template the Go template of the last.
+type ExternalName struct {
+	Strategy, Template string
 }
 
 // NewBaseTemplateProduct creates a new base template product.
@@ -48,14 +101,36 @@ func (t *BaseTemplateProduct) Configure(cfg config.Config) error {
 		t.DomainMixin = machinery.DomainMixin{Domain: t.Domain}
 		t.Repo = cfg.GetRepository()
 		t.RepositoryMixin = machinery.RepositoryMixin{Repo: t.Repo}
+
+		settings, err := core.LoadProjectSettings(cfg)
+		if err != nil {
+			return err
+		}
+		t.ClusterProviderConfig = settings.ClusterProviderConfig
+		t.WebhookServer = len(settings.StorageVersions) > 0
+		t.RegistryOrgs = settings.Generator.Defaults.RegistryOrgs
+		t.License = settings.License
+
+		resources, err := cfg.GetResources()
+		if err != nil {
+			return err
+		}
+		for _, res := range resources {
+			if res.HasDefaultingWebhook() || res.HasValidationWebhook() {
+				t.WebhookServer = true
+			}
+		}
 	}
 
+	if len(t.RegistryOrgs) == 0 {
+		t.RegistryOrgs = core.DefaultRegistryOrgs
+	}
 	if t.ProviderName == "" && t.Repo != "" {
 		t.ProviderName = core.ExtractProviderName(t.Repo)
 	}
 
-	// Set default boilerplate
-	t.Boilerplate = DefaultBoilerplate()
+	t.License = t.License.WithDefaults()
+	t.Boilerplate = Boilerplate(t.License)
 	t.BoilerplateMixin = machinery.BoilerplateMixin{Boilerplate: t.Boilerplate}
 
 	return nil
@@ -70,6 +145,32 @@ func (t *BaseTemplateProduct) SetResource(res *resource.Resource) error {
 	return nil
 }
 
+// AdmissionWebhook reports whether the resource has a defaulting or validating
+// webhook, added by `create webhook`.
+func (t *BaseTemplateProduct) AdmissionWebhook() bool {
+	return t.Resource != nil && (t.Resource.HasDefaultingWebhook() || t.Resource.HasValidationWebhook())
+}
+
+// WebhookPath returns the path controller-runtime serves the resource's
+// admission webhook on, for op "mutate" or "validate". The resource's
+// +kubebuilder:webhook markers must name the same path.
+func (t *BaseTemplateProduct) WebhookPath(op string) string {
+	return fmt.Sprintf("/%s-%s-%s-%s", op, strings.ReplaceAll(t.Resource.QualifiedGroup(), ".", "-"),
+		t.Resource.Version, strings.ToLower(t.Resource.Kind))
+}
+
+// RegistryOrgList returns the registry orgs space-separated, as the Makefile
+// takes them; with a prefix, only those that start with it.
+func (t *BaseTemplateProduct) RegistryOrgList(prefix ...string) string {
+	var orgs []string
+	for _, org := range t.RegistryOrgs {
+		if len(prefix) == 0 || strings.HasPrefix(org, prefix[0]) {
+			orgs = append(orgs, org)
+		}
+	}
+	return strings.Join(orgs, " ")
+}
+
 // SetForce makes the template overwrite an existing file. It is only called
 // for --force; the zero-value action (machinery.SkipFile) is the default.
 func (t *BaseTemplateProduct) SetForce(force bool) {
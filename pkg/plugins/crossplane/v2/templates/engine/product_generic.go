@@ -40,7 +40,7 @@ func NewGenericTemplateProduct(outputPath, templatePath string) *GenericTemplate
 	}
 }
 
-// SetTemplateDefaults loads the template body from the embedded filesystem.
+// SetTemplateDefaults loads the template body, the overlay's if it has one.
 func (t *GenericTemplateProduct) SetTemplateDefaults() error {
 	if t.Path == "" {
 		t.Path = t.outputPath
@@ -126,3 +126,23 @@ func TestControllerRegisterGenerator_PerKind(t *testing.T) {
 		}
 	}
 }
+
+func TestRegisterGenerators_MultiVersionKind(t *testing.T) {
+	// One kind served in two versions: a scheme per version, one controller.
+	resources := []resource.Resource{
+		{GVK: resource.GVK{Group: "storage", Version: "v1alpha1", Kind: "Bucket"}},
+		{GVK: resource.GVK{Group: "storage", Version: "v1beta1", Kind: "Bucket"}},
+	}
+
+	api := render(t, NewAPIRegisterGenerator(testRepo, "provider-test", resources))
+	for _, alias := range []string{"storagev1alpha1", "storagev1beta1"} {
+		if n := strings.Count(api, alias+".SchemeBuilder.AddToScheme"); n != 1 {
+			t.Errorf("%s registration count = %d, want 1\n%s", alias, n, api)
+		}
+	}
+
+	ctrl := NewControllerRegisterGenerator(testRepo, "provider-test", resources)
+	if len(ctrl.Controllers) != 2 || ctrl.Controllers[1].Setup != "bucket.SetupGated" {
+		t.Errorf("Controllers = %+v, want config.Setup and one bucket.SetupGated", ctrl.Controllers)
+	}
+}
@@ -0,0 +1,34 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+// Seam is a set of names tool-owned code calls in a user-owned file. The user
+// may change anything about them but the names and signatures.
+type Seam struct {
+	Names []string
+	// File is where the scaffold defines them; <kind> stands for each kind's
+	// controller directory. The names may move to another file of the package.
+	File string
+}
+
+// Seams are the provider's seams, as listed in docs/ownership.md and checked
+// by `doctor`.
+var Seams = []Seam{
+	{Names: []string{"Client", "NewClient"}, File: "internal/provider/client.go"},
+	{Names: []string{"Flags", "Configure"}, File: "internal/provider/options.go"},
+	{Names: []string{"NewExternal", "ReconcilerOptions"}, File: "internal/controller/<kind>/external.go"},
+}
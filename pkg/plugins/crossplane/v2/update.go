@@ -31,6 +31,7 @@ import (
 	"sigs.k8s.io/kubebuilder/v4/pkg/config/store"
 	"sigs.k8s.io/kubebuilder/v4/pkg/config/store/yaml"
 	"sigs.k8s.io/kubebuilder/v4/pkg/machinery"
+	"sigs.k8s.io/kubebuilder/v4/pkg/model/resource"
 
 	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/core"
 	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/templates/engine"
@@ -42,7 +43,10 @@ import (
 // NewUpdateCommand returns the `update` command, registered on the CLI via
 // cli.WithExtraCommands (kubebuilder's plugin interface has no update hook).
 func NewUpdateCommand() *cobra.Command {
-	var adopt bool
+	var (
+		adopt, dryRun, keep, worktree bool
+		opts                          updateOptions
+	)
 	cmd := &cobra.Command{
 		Use:   "update",
 		Short: "Refresh tool-owned core files of an existing provider",
@@ -56,54 +60,142 @@ left alone. go.mod itself is never overwritten — only its framework dependency
 are bumped via 'go get', so your own requires are preserved.
 
 The working tree must be clean; the result is left uncommitted so you can review it with
-'git diff' before committing. If a step fails midway, run 'git reset --hard' to revert.
+'git diff' before committing. If any step fails, every change is rolled back and the tree
+is left as it was; pass --keep-on-failure to leave the failed state in place instead.
+
+Layout changes made since the generator version stamped in PROJECT are applied first,
+as migrations; each is recorded in PROJECT so it runs once, and anything it leaves to you
+is printed as ACTION NEEDED.
+
+Every tool-owned file the generator writes is recorded by content hash in
+.xp-provider-gen.lock. If one has been edited since, update names it, shows the edit it
+would discard, and stops; move the edit into a user-owned file, or pass --force to
+discard it.
+
+Tool-owned files the current templates no longer produce — from a template that was
+renamed or removed, or a kind gone from PROJECT — are listed; --prune deletes them.
+
+Use --worktree to leave your checkout alone: the update runs in a temporary git worktree
+made from HEAD and is committed, with a provenance trailer, on a new branch
+xp-provider-gen/update-<version>, ready to push as a pull request. It needs no clean tree.
 
 Use --adopt once on a provider generated before the ownership contract existed: it stamps
-provenance and writes the header onto recognized tool-owned files so plain 'update' works.`,
-		RunE: func(_ *cobra.Command, _ []string) error {
-			run := runUpdate
+provenance and writes the header onto recognized tool-owned files so plain 'update' works.
+
+Use --dry-run to preview the update without writing anything: it prints a unified diff for
+every file that would be added or refreshed, and the dependency versions 'go get' would
+apply. It does not need a clean tree, and exits non-zero when changes are pending, so CI
+can flag a provider that has fallen behind the generator.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if dryRun {
+				// Pending changes are a result, not a usage mistake.
+				cmd.SilenceUsage = true
+				return runDryRun(cmd.OutOrStdout())
+			}
+			if worktree {
+				return runUpdateInWorktree(context.Background(), opts, keep)
+			}
 			if adopt {
-				run = runAdopt
+				return withRollback(context.Background(), keep, runAdopt)
 			}
-			if err := run(context.Background()); err != nil {
-				return fmt.Errorf("%w\n  changes are uncommitted; run 'git reset --hard' to revert", err)
+			if err := withRollback(context.Background(), keep, func(ctx context.Context) error {
+				return runUpdate(ctx, opts)
+			}); err != nil {
+				return err
 			}
+			fmt.Println("\nUpdate complete. Review the changes with 'git diff' and commit when ready.")
 			return nil
 		},
 	}
 	cmd.Flags().BoolVar(&adopt, "adopt", false,
 		"retrofit an existing provider: stamp provenance and add the generated header to "+
 			"tool-owned files, then exit (run 'update' afterward to refresh them)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false,
+		"print the changes update would make as unified diffs, without writing anything; "+
+			"exits non-zero when changes are pending")
+	cmd.Flags().BoolVar(&opts.force, "force", false,
+		"overwrite tool-owned files even if they were edited since they were generated")
+	cmd.Flags().BoolVar(&opts.prune, "prune", false,
+		"delete tool-owned files the current templates no longer produce")
+	cmd.MarkFlagsMutuallyExclusive("adopt", "dry-run", "force")
+	cmd.MarkFlagsMutuallyExclusive("adopt", "dry-run", "prune")
+	cmd.Flags().BoolVar(&keep, "keep-on-failure", false,
+		"leave the changes of a failed update (or --adopt) in place for debugging instead of rolling them back")
+	cmd.MarkFlagsMutuallyExclusive("dry-run", "keep-on-failure")
+	cmd.Flags().BoolVar(&worktree, "worktree", false,
+		"run the update in a temporary git worktree and commit it on a new branch, leaving this checkout untouched")
+	cmd.MarkFlagsMutuallyExclusive("adopt", "dry-run", "worktree")
 	return cmd
 }
 
-// provenance records, in PROJECT, the generator version that last touched the project.
-type provenance struct {
-	Version string `json:"version"`
+// withRollback runs fn, which writes to the project, on a clean working tree. If
+// fn fails, the tree is put back exactly as it was: the files fn rendered, the
+// go.mod bumps, whatever `make generate` wrote. keep skips that, leaving the
+// failure in place to debug. The clean tree is what makes the restore exact —
+// there is nothing uncommitted of the user's for it to take away.
+func withRollback(ctx context.Context, keep bool, fn func(context.Context) error) error {
+	if err := requireCleanTree(ctx); err != nil {
+		return err
+	}
+	git := core.NewGitCommandRunner("")
+	head, err := git.Head(ctx)
+	if err != nil {
+		return fmt.Errorf("recording the commit to roll back to (does the repository have one yet?): %w", err)
+	}
+
+	err = fn(ctx)
+	if err == nil {
+		return nil
+	}
+	if keep {
+		return fmt.Errorf("%w\n  changes are left in place (--keep-on-failure); "+
+			"run 'git reset --hard && git clean -fd' to revert", err)
+	}
+	// fn may have failed because ctx ended; the restore must run regardless.
+	if rerr := git.Restore(context.WithoutCancel(ctx), head); rerr != nil {
+		return fmt.Errorf("%w\n  rolling back failed too (%v); "+
+			"run 'git reset --hard %s && git clean -fd' to revert", err, rerr, head)
+	}
+	return fmt.Errorf("%w\n  all changes were rolled back", err)
 }
 
-// prepare enforces the clean-tree precondition, loads the project, and renders the
-// current template set into an in-memory FS. Both update and adopt start here.
-func prepare(ctx context.Context) (store.Store, afero.Fs, error) {
-	if err := requireCleanTree(ctx); err != nil {
+// loadAndRender loads and validates PROJECT, then renders the current template set
+// into an in-memory FS. `update --adopt` and --dry-run start here.
+func loadAndRender() (store.Store, afero.Fs, error) {
+	st, err := loadValidProject()
+	if err != nil {
 		return nil, nil, err
 	}
-	st, err := loadProjectStore()
+	mem, err := renderProject(st.Config())
 	if err != nil {
 		return nil, nil, err
 	}
+	return st, mem, nil
+}
+
+// loadValidProject loads PROJECT and validates it.
+func loadValidProject() (store.Store, error) {
+	st, err := loadProjectStore()
+	if err != nil {
+		return nil, err
+	}
 	if err := validateProject(st.Config()); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
+	return st, nil
+}
+
+// renderProject renders the current template set into a new in-memory FS.
+func renderProject(cfg config.Config) (afero.Fs, error) {
 	mem := afero.NewMemMapFs()
-	if err := renderToMemFS(st.Config(), machinery.Filesystem{FS: mem}); err != nil {
-		return nil, nil, fmt.Errorf("rendering tool-owned files: %w", err)
+	if err := renderToMemFS(cfg, machinery.Filesystem{FS: mem}); err != nil {
+		return nil, fmt.Errorf("rendering tool-owned files: %w", err)
 	}
-	return st, mem, nil
+	return mem, nil
 }
 
-func runAdopt(ctx context.Context) error {
-	store, mem, err := prepare(ctx)
+func runAdopt(_ context.Context) error {
+	store, mem, err := loadAndRender()
 	if err != nil {
 		return err
 	}
@@ -191,25 +283,84 @@ func insertGeneratedHeader(content []byte) []byte {
 	return []byte(header + s)
 }
 
-// stampProvenance records the current generator version in PROJECT and saves it.
+// stampProvenance records the current generator version in PROJECT and saves it,
+// keeping the rest of the plugin's settings as they were.
 func stampProvenance(store store.Store) error {
-	if err := store.Config().EncodePluginConfig(pluginName, provenance{Version: version.Get().Version}); err != nil {
-		return fmt.Errorf("encoding provenance: %w", err)
+	settings, err := core.LoadProjectSettings(store.Config())
+	if err != nil {
+		return err
+	}
+	settings.Version = version.Get().Version
+	if err := core.SaveProjectSettings(store.Config(), settings); err != nil {
+		return err
 	}
 	return store.Save()
 }
 
-func runUpdate(ctx context.Context) error {
-	store, mem, err := prepare(ctx)
+// updateOptions are the flags of a plain `update`.
+type updateOptions struct {
+	force bool
+	prune bool
+}
+
+func runUpdate(ctx context.Context, opts updateOptions) error {
+	store, err := loadValidProject()
 	if err != nil {
 		return err
 	}
+	disk := afero.NewOsFs()
 
-	result, err := reconcile(mem, afero.NewOsFs())
+	// Migrations run first: what they move and rename is what the render and
+	// the ownership gate then work on.
+	if err := runMigrations(store.Config(), disk, version.Get().Version, os.Stdout); err != nil {
+		return err
+	}
+	mem, err := renderProject(store.Config())
+	if err != nil {
+		return err
+	}
+
+	drifted, err := findDrift(mem, disk)
+	if err != nil {
+		return fmt.Errorf("checking tool-owned files for edits: %w", err)
+	}
+	orphans, err := findOrphans(mem, disk)
+	if err != nil {
+		return fmt.Errorf("finding stale tool-owned files: %w", err)
+	}
+	if opts.prune {
+		edited, err := orphanDrift(disk, orphans)
+		if err != nil {
+			return fmt.Errorf("checking tool-owned files for edits: %w", err)
+		}
+		drifted = append(drifted, edited...)
+	}
+	if len(drifted) > 0 {
+		if !opts.force {
+			printDrift(os.Stdout, drifted)
+			return fmt.Errorf("refusing to overwrite %d edited tool-owned file(s); move the edits "+
+				"into a user-owned file, or re-run with --force to discard them", len(drifted))
+		}
+		fmt.Printf("Overwriting %d edited tool-owned file(s) (--force).\n", len(drifted))
+	}
+	if opts.prune && len(orphans) > 0 {
+		if err := pruneOrphans(disk, orphans); err != nil {
+			return fmt.Errorf("deleting stale tool-owned files: %w", err)
+		}
+		fmt.Printf("Deleted %d tool-owned file(s) the generator no longer produces:\n", len(orphans))
+		printPaths(os.Stdout, orphans)
+	}
+
+	result, err := reconcile(mem, disk)
 	if err != nil {
 		return fmt.Errorf("reconciling generated files: %w", err)
 	}
 	result.print()
+	if !opts.prune && len(orphans) > 0 {
+		fmt.Printf("%d tool-owned file(s) are no longer generated; re-run with --prune to delete them:\n",
+			len(orphans))
+		printPaths(os.Stdout, orphans)
+	}
 
 	if err := applyDependencies(ctx); err != nil {
 		return err
@@ -229,8 +380,6 @@ func runUpdate(ctx context.Context) error {
 	if err := stampProvenance(store); err != nil {
 		return fmt.Errorf("stamping provenance: %w", err)
 	}
-
-	fmt.Println("\nUpdate complete. Review the changes with 'git diff' and commit when ready.")
 	return nil
 }
 
@@ -300,7 +449,7 @@ func renderToMemFS(cfg config.Config, memFS machinery.Filesystem) error {
 
 	base := machinery.NewScaffold(memFS,
 		machinery.WithConfig(cfg),
-		machinery.WithBoilerplate(engine.DefaultBoilerplate()),
+		machinery.WithBoilerplate(engine.ProjectBoilerplate(cfg)),
 	)
 	builders := engine.AsBuilders(initTemplates)
 	builders = append(builders, engine.CoreGenerators(cfg, resources)...)
@@ -309,40 +458,62 @@ func renderToMemFS(cfg config.Config, memFS machinery.Filesystem) error {
 	}
 
 	for _, res := range resources {
-		apiTemplates, err := factory.GetAPITemplates(engine.WithForce(true), engine.WithResource(&res))
-		if err != nil {
-			return fmt.Errorf("api templates for %s: %w", res.Kind, err)
-		}
-		apiScaffold := machinery.NewScaffold(memFS,
-			machinery.WithConfig(cfg),
-			machinery.WithBoilerplate(engine.DefaultBoilerplate()),
-			machinery.WithResource(&res),
-		)
-		if err := apiScaffold.Execute(engine.AsBuilders(apiTemplates)...); err != nil {
-			return fmt.Errorf("rendering api templates for %s: %w", res.Kind, err)
+		if err := renderAPIToMemFS(cfg, factory, res, memFS); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
+// renderAPIToMemFS renders one resource's API templates into the given in-memory
+// filesystem. For a kind served in several versions, the storage version recorded
+// in PROJECT decides which of them renders the controller and conversion files.
+func renderAPIToMemFS(cfg config.Config, factory engine.TemplateFactory, res resource.Resource,
+	memFS machinery.Filesystem,
+) error {
+	settings, err := core.LoadProjectSettings(cfg)
+	if err != nil {
+		return err
+	}
+	apiTemplates, err := factory.GetAPITemplates(engine.WithForce(true), engine.WithResource(&res),
+		engine.WithHubVersion(settings.StorageVersion(res)))
+	if err != nil {
+		return fmt.Errorf("api templates for %s: %w", res.Kind, err)
+	}
+	apiScaffold := machinery.NewScaffold(memFS,
+		machinery.WithConfig(cfg),
+		machinery.WithBoilerplate(engine.ProjectBoilerplate(cfg)),
+		machinery.WithResource(&res),
+	)
+	if err := apiScaffold.Execute(engine.AsBuilders(apiTemplates)...); err != nil {
+		return fmt.Errorf("rendering api templates for %s: %w", res.Kind, err)
+	}
+	return nil
+}
+
 // reconcile copies every rendered file from src onto dst through the ownership
 // gate: tool-owned (headered) files are overwritten, new files seeded, and
-// user-owned (headerless) files left untouched.
+// user-owned (headerless) files left untouched. What it writes is recorded in
+// the lock.
 func reconcile(src, dst afero.Fs) (reconcileResult, error) {
 	var result reconcileResult
+	locked := trackWrites(dst)
 	err := afero.Walk(src, ".", func(path string, info fs.FileInfo, walkErr error) error {
 		if walkErr != nil || info.IsDir() {
 			return walkErr
 		}
 		rel := strings.TrimPrefix(filepath.ToSlash(path), "/")
-		decision, err := applyFile(src, dst, path, rel)
+		decision, err := applyFile(src, locked, path, rel)
 		if err != nil {
 			return err
 		}
 		result.record(decision, rel)
 		return nil
 	})
-	return result, err
+	if err != nil {
+		return result, err
+	}
+	return result, locked.saveLock()
 }
 
 // checkContained rejects a rendered path that would write outside the project
@@ -366,16 +537,10 @@ func applyFile(src, dst afero.Fs, srcPath, rel string) (core.WriteDecision, erro
 	if err := checkContained(rel); err != nil {
 		return core.Skip, err
 	}
-	exists, err := afero.Exists(dst, rel)
+	exists, existing, err := readExisting(dst, rel)
 	if err != nil {
 		return core.Skip, err
 	}
-	var existing []byte
-	if exists {
-		if existing, err = afero.ReadFile(dst, rel); err != nil {
-			return core.Skip, err
-		}
-	}
 
 	decision := core.DecideWrite(exists, existing)
 	if decision == core.Skip {
@@ -398,6 +563,20 @@ func applyFile(src, dst afero.Fs, srcPath, rel string) (core.WriteDecision, erro
 	return decision, afero.WriteFile(dst, rel, newContent, mode)
 }
 
+// readExisting reads the on-disk counterpart of a rendered file, reporting whether
+// it exists so the result can be fed straight into core.DecideWrite.
+func readExisting(dst afero.Fs, rel string) (bool, []byte, error) {
+	exists, err := afero.Exists(dst, rel)
+	if err != nil || !exists {
+		return false, nil, err
+	}
+	existing, err := afero.ReadFile(dst, rel)
+	if err != nil {
+		return false, nil, err
+	}
+	return true, existing, nil
+}
+
 type reconcileResult struct {
 	overwritten []string
 	seeded      []string
@@ -0,0 +1,182 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+	"golang.org/x/mod/modfile"
+
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/core"
+	"github.com/cychiang/xp-provider-gen/pkg/version"
+	"github.com/cychiang/xp-provider-gen/pkg/versions"
+)
+
+// runDryRun reports every change `update` would make — file diffs and dependency
+// bumps — without writing anything. It returns an error when changes are pending,
+// so a CI job running `update --dry-run` fails once a provider falls behind.
+func runDryRun(out io.Writer) error {
+	st, mem, err := loadAndRender()
+	if err != nil {
+		return err
+	}
+	disk := afero.NewOsFs()
+
+	due, err := pendingMigrations(st.Config(), disk, version.Get().Version)
+	if err != nil {
+		return err
+	}
+	for _, mig := range due {
+		fmt.Fprintf(out, "migration %s: %s\n", mig.id, mig.summary)
+	}
+
+	changed, err := previewReconcile(mem, disk, out)
+	if err != nil {
+		return fmt.Errorf("previewing generated files: %w", err)
+	}
+	drifted, err := findDrift(mem, disk)
+	if err != nil {
+		return fmt.Errorf("checking tool-owned files for edits: %w", err)
+	}
+	for _, d := range drifted {
+		fmt.Fprintf(out, "%s was edited since it was generated; update needs --force to overwrite it\n", d.path)
+	}
+	orphans, err := findOrphans(mem, disk)
+	if err != nil {
+		return fmt.Errorf("finding stale tool-owned files: %w", err)
+	}
+	for _, rel := range orphans {
+		fmt.Fprintf(out, "%s is no longer generated; update --prune would delete it\n", rel)
+	}
+	bumps, err := pendingDependencies(disk)
+	if err != nil {
+		return err
+	}
+	for _, b := range bumps {
+		fmt.Fprintf(out, "go get %s@%s (currently %s)\n", b.Module, b.Version, b.current())
+	}
+
+	if len(changed) == 0 && len(bumps) == 0 && len(due) == 0 && len(orphans) == 0 {
+		fmt.Fprintf(out, "Provider is up to date with generator %s.\n", version.Get().Version)
+		return nil
+	}
+	return fmt.Errorf("update would run %d migration(s), change %d file(s) and %d dependency "+
+		"version(s), and prune %d file(s); run 'xp-provider-gen update' to apply them",
+		len(due), len(changed), len(bumps), len(orphans))
+}
+
+// previewReconcile runs every rendered file in src through the same ownership gate
+// as reconcile, but writes a unified diff to out instead of touching dst. Files
+// that would be overwritten with identical content are not changes and print
+// nothing. It returns the paths that would change.
+func previewReconcile(src, dst afero.Fs, out io.Writer) ([]string, error) {
+	var changed []string
+	err := afero.Walk(src, ".", func(path string, info fs.FileInfo, walkErr error) error {
+		if walkErr != nil || info.IsDir() {
+			return walkErr
+		}
+		rel := strings.TrimPrefix(filepath.ToSlash(path), "/")
+		diff, err := previewFile(src, dst, path, rel)
+		if err != nil {
+			return err
+		}
+		if diff != "" {
+			changed = append(changed, rel)
+			fmt.Fprint(out, diff)
+		}
+		return nil
+	})
+	return changed, err
+}
+
+// previewFile returns the diff applyFile would produce for one rendered file, or
+// "" when the gate skips it or the content is unchanged.
+func previewFile(src, dst afero.Fs, srcPath, rel string) (string, error) {
+	if err := checkContained(rel); err != nil {
+		return "", err
+	}
+	exists, existing, err := readExisting(dst, rel)
+	if err != nil {
+		return "", err
+	}
+	decision := core.DecideWrite(exists, existing)
+	if decision == core.Skip {
+		return "", nil
+	}
+	rendered, err := afero.ReadFile(src, srcPath)
+	if err != nil {
+		return "", err
+	}
+	if bytes.Equal(existing, rendered) {
+		return "", nil
+	}
+	from := "a/" + rel
+	if decision == core.Seed {
+		from = "/dev/null"
+	}
+	return unifiedDiff(from, "b/"+rel, existing, rendered), nil
+}
+
+// dependencyBump is a manifest dependency whose version differs from go.mod's.
+type dependencyBump struct {
+	versions.Dependency
+
+	// Current is the version go.mod requires today, or "" if it has no require.
+	Current string
+}
+
+func (b dependencyBump) current() string {
+	if b.Current == "" {
+		return "not required"
+	}
+	return b.Current
+}
+
+// pendingDependencies compares the manifest with go.mod's requires and returns the
+// versions applyDependencies would change with `go get`.
+func pendingDependencies(dst afero.Fs) ([]dependencyBump, error) {
+	deps, err := versions.GoModDependencies()
+	if err != nil {
+		return nil, fmt.Errorf("loading dependency manifest: %w", err)
+	}
+	data, err := afero.ReadFile(dst, "go.mod")
+	if err != nil {
+		return nil, fmt.Errorf("reading go.mod: %w", err)
+	}
+	mod, err := modfile.ParseLax("go.mod", data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("parsing go.mod: %w", err)
+	}
+	required := make(map[string]string, len(mod.Require))
+	for _, r := range mod.Require {
+		required[r.Mod.Path] = r.Mod.Version
+	}
+
+	var bumps []dependencyBump
+	for _, d := range deps {
+		if current := required[d.Module]; current != d.Version {
+			bumps = append(bumps, dependencyBump{Dependency: d, Current: current})
+		}
+	}
+	return bumps, nil
+}
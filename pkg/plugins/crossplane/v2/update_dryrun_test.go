@@ -0,0 +1,158 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/core"
+	"github.com/cychiang/xp-provider-gen/pkg/versions"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   string
+	}{
+		{
+			name:   "identical",
+			before: "a\nb\n",
+			after:  "a\nb\n",
+			want:   "",
+		},
+		{
+			name:  "new file",
+			after: "a\nb\n",
+			want:  "--- from\n+++ to\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name:   "changed line keeps three lines of context",
+			before: "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			after:  "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			want:   "--- from\n+++ to\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			name:   "distant changes become separate hunks",
+			before: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			after:  "one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n",
+			want: "--- from\n+++ to\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n" +
+				"@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+ten\n",
+		},
+		{
+			name:   "missing trailing newline is flagged",
+			before: "a\n",
+			after:  "a\nb",
+			want:   "--- from\n+++ to\n@@ -1 +1,2 @@\n a\n+b\n\\ No newline at end of file\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := unifiedDiff("from", "to", []byte(tt.before), []byte(tt.after))
+			if got != tt.want {
+				t.Errorf("unifiedDiff() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreviewReconcile(t *testing.T) {
+	const headered = core.GeneratedHeader + "\npackage foo\n// new\n"
+	const oldHeadered = core.GeneratedHeader + "\npackage foo\n// old\n"
+
+	src := afero.NewMemMapFs()
+	dst := afero.NewMemMapFs()
+
+	// Tool-owned, out of date -> diffed.
+	_ = afero.WriteFile(src, "cmd/provider/main.go", []byte(headered), 0o644)
+	_ = afero.WriteFile(dst, "cmd/provider/main.go", []byte(oldHeadered), 0o644)
+	// Tool-owned, already current -> no change.
+	_ = afero.WriteFile(src, "apis/register.go", []byte(headered), 0o644)
+	_ = afero.WriteFile(dst, "apis/register.go", []byte(headered), 0o644)
+	// User-owned -> skipped by the gate.
+	_ = afero.WriteFile(src, "internal/provider/client.go", []byte("package provider\n// stub\n"), 0o644)
+	_ = afero.WriteFile(dst, "internal/provider/client.go", []byte("package provider\n// mine\n"), 0o644)
+	// Absent -> seeded from /dev/null.
+	_ = afero.WriteFile(src, "config/config.go", []byte(headered), 0o644)
+
+	var out bytes.Buffer
+	changed, err := previewReconcile(src, dst, &out)
+	if err != nil {
+		t.Fatalf("previewReconcile: %v", err)
+	}
+
+	if len(changed) != 2 {
+		t.Errorf("changed = %v, want main.go and config.go only", changed)
+	}
+	assertContains(t, "changed", changed, "cmd/provider/main.go")
+	assertContains(t, "changed", changed, "config/config.go")
+	for _, want := range []string{
+		"--- a/cmd/provider/main.go\n+++ b/cmd/provider/main.go\n",
+		"-// old\n+// new\n",
+		"--- /dev/null\n+++ b/config/config.go\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("preview output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	// Nothing may be written.
+	if exists, _ := afero.Exists(dst, "config/config.go"); exists {
+		t.Error("dry run seeded config/config.go; it must not write")
+	}
+	got, _ := afero.ReadFile(dst, "cmd/provider/main.go")
+	if string(got) != oldHeadered {
+		t.Errorf("dry run overwrote main.go: %q", got)
+	}
+}
+
+func TestPendingDependencies(t *testing.T) {
+	deps, err := versions.GoModDependencies()
+	if err != nil || len(deps) < 2 {
+		t.Fatalf("manifest: %v (%d deps)", err, len(deps))
+	}
+
+	// go.mod pins the first dependency at the manifest version, the second at
+	// an older pre-release, and omits the rest.
+	older := deps[1].Version + "-rc.1"
+	gomod := "module example.com/provider\n\ngo 1.26.0\n\nrequire (\n" +
+		"\t" + deps[0].Module + " " + deps[0].Version + "\n" +
+		"\t" + deps[1].Module + " " + older + "\n)\n"
+	fs := afero.NewMemMapFs()
+	_ = afero.WriteFile(fs, "go.mod", []byte(gomod), 0o644)
+
+	bumps, err := pendingDependencies(fs)
+	if err != nil {
+		t.Fatalf("pendingDependencies: %v", err)
+	}
+	if len(bumps) != len(deps)-1 {
+		t.Fatalf("got %d bumps, want %d (every dependency but the current one)", len(bumps), len(deps)-1)
+	}
+	for _, b := range bumps {
+		if b.Module == deps[0].Module {
+			t.Errorf("%s is already current and must not be bumped", b.Module)
+		}
+		if b.Module == deps[1].Module && b.Current != older {
+			t.Errorf("%s current = %q, want %q", b.Module, b.Current, older)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/afero"
+
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/core"
+)
+
+// findOrphans returns the tool-owned files in the module on dst that src, the
+// current render, does not contain: left behind by a template that was renamed
+// or removed, or by a kind that has left PROJECT. reconcile only walks what is
+// rendered, so nothing else would ever delete them. Files without the header
+// are the user's and are never listed.
+func findOrphans(src, dst afero.Fs) ([]string, error) {
+	var orphans []string
+	err := walkModule(dst, func(rel string) error {
+		rel = filepath.ToSlash(rel)
+		if rendered, err := afero.Exists(src, rel); err != nil || rendered {
+			return err
+		}
+		b, err := afero.ReadFile(dst, rel)
+		if err != nil {
+			return err
+		}
+		if core.IsToolOwned(b) {
+			orphans = append(orphans, rel)
+		}
+		return nil
+	})
+	sort.Strings(orphans)
+	return orphans, err
+}
+
+// orphanDrift returns the orphans edited since the generator wrote them, with
+// the diff pruning would apply: the whole file removed.
+func orphanDrift(dst afero.Fs, orphans []string) ([]driftedFile, error) {
+	lock, err := core.ReadLock(dst)
+	if err != nil {
+		return nil, err
+	}
+	var drifted []driftedFile
+	for _, rel := range orphans {
+		b, err := afero.ReadFile(dst, rel)
+		if err != nil {
+			return nil, err
+		}
+		if lock.Drifted(rel, b) {
+			drifted = append(drifted, driftedFile{path: rel, diff: unifiedDiff("a/"+rel, "/dev/null", b, nil)})
+		}
+	}
+	return drifted, nil
+}
+
+// pruneOrphans deletes the orphans, and the directories they leave empty, the
+// way `delete api` removes a kind's files.
+func pruneOrphans(dst afero.Fs, orphans []string) error {
+	return deletionPlan{remove: orphans}.apply(dst)
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"sigs.k8s.io/kubebuilder/v4/pkg/machinery"
+
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/core"
+)
+
+func TestFindAndPruneOrphans(t *testing.T) {
+	src := afero.NewMemMapFs()
+	if err := renderToMemFS(webhookProject(t, nil), machinery.Filesystem{FS: src}); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	dst := afero.NewMemMapFs()
+	if _, err := reconcile(src, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	generated := []byte(core.GeneratedHeader + "\npackage disk\n")
+	for path, content := range map[string][]byte{
+		// A kind that has left PROJECT: its wiring is stale, its logic is the user's.
+		"internal/controller/disk/wiring.go":   generated,
+		"internal/controller/disk/external.go": []byte("package disk\n"),
+		// A template the generator no longer has.
+		"internal/controller/bucket/setup.go": []byte(core.GeneratedHeader + "\npackage bucket\n"),
+		// The user's own file next to tool-owned ones.
+		"internal/controller/bucket/helpers.go": []byte("package bucket\n"),
+		// Not part of the module.
+		"build/makelib/setup.go": generated,
+	} {
+		_ = afero.WriteFile(dst, path, content, 0o644)
+	}
+	_ = afero.WriteFile(dst, "build/go.mod", []byte("module build\n"), 0o644)
+
+	orphans, err := findOrphans(src, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"internal/controller/bucket/setup.go", "internal/controller/disk/wiring.go"}
+	if !slices.Equal(orphans, want) {
+		t.Fatalf("findOrphans() = %v, want %v", orphans, want)
+	}
+
+	if err := pruneOrphans(dst, orphans); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range want {
+		if exists, _ := afero.Exists(dst, path); exists {
+			t.Errorf("%s was not pruned", path)
+		}
+	}
+	for _, path := range []string{
+		"internal/controller/disk/external.go",
+		"internal/controller/bucket/helpers.go",
+		"internal/controller/bucket/wiring.go",
+	} {
+		if exists, _ := afero.Exists(dst, path); !exists {
+			t.Errorf("%s was pruned", path)
+		}
+	}
+}
+
+func TestOrphanDrift(t *testing.T) {
+	dst := afero.NewMemMapFs()
+	const stale = "internal/controller/disk/wiring.go"
+	generated := []byte(core.GeneratedHeader + "\npackage disk\n")
+	locked := trackWrites(dst)
+	_ = afero.WriteFile(locked, stale, generated, 0o644)
+	if err := locked.saveLock(); err != nil {
+		t.Fatal(err)
+	}
+
+	if drifted, err := orphanDrift(dst, []string{stale}); err != nil || len(drifted) != 0 {
+		t.Fatalf("orphanDrift() on an untouched file = %v, %v; want nothing", drifted, err)
+	}
+	_ = afero.WriteFile(dst, stale, append(generated, "// my fix\n"...), 0o644)
+	drifted, err := orphanDrift(dst, []string{stale})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drifted) != 1 || !strings.Contains(drifted[0].diff, "+++ /dev/null") {
+		t.Errorf("orphanDrift() = %+v, want %s shown as deleted", drifted, stale)
+	}
+}
@@ -17,6 +17,10 @@ limitations under the License.
 package v2
 
 import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
 	"slices"
 	"strings"
 	"testing"
@@ -134,3 +138,70 @@ func assertContains(t *testing.T, label string, list []string, want string) {
 		t.Errorf("%s = %v, want to contain %q", label, list, want)
 	}
 }
+
+// gitProject makes a temporary git repository with one commit holding go.mod,
+// and makes it the working directory for the rest of the test. It returns a
+// function to run git in it, output trimmed.
+func gitProject(t *testing.T) func(args ...string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Chdir(t.TempDir())
+	t.Setenv("GIT_AUTHOR_NAME", "t")
+	t.Setenv("GIT_AUTHOR_EMAIL", "t@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "t")
+	t.Setenv("GIT_COMMITTER_EMAIL", "t@example.com")
+	git := func(args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", args...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "--quiet")
+	_ = os.WriteFile("go.mod", []byte("module example.com/p\n"), 0o644)
+	git("add", "go.mod")
+	git("commit", "--quiet", "-m", "init")
+	return git
+}
+
+func TestWithRollback(t *testing.T) {
+	gitProject(t)
+
+	failing := func(context.Context) error {
+		_ = os.WriteFile("go.mod", []byte("module example.com/p\n\nrequire x v1\n"), 0o644)
+		_ = os.MkdirAll("apis/v1alpha1", 0o755)
+		_ = os.WriteFile("apis/v1alpha1/types.go", []byte("package v1alpha1\n"), 0o644)
+		return errors.New("make generate failed")
+	}
+
+	err := withRollback(context.Background(), false, failing)
+	if err == nil || !strings.Contains(err.Error(), "rolled back") {
+		t.Fatalf("withRollback() = %v, want the failure reported as rolled back", err)
+	}
+	if b, _ := os.ReadFile("go.mod"); string(b) != "module example.com/p\n" {
+		t.Errorf("go.mod = %q, want it restored", b)
+	}
+	if _, err := os.Stat("apis"); !os.IsNotExist(err) {
+		t.Errorf("apis/ survived the rollback: %v", err)
+	}
+
+	err = withRollback(context.Background(), true, failing)
+	if err == nil || !strings.Contains(err.Error(), "--keep-on-failure") {
+		t.Fatalf("withRollback(keep) = %v, want the failure reported as kept", err)
+	}
+	if _, err := os.Stat("apis/v1alpha1/types.go"); err != nil {
+		t.Errorf("--keep-on-failure removed what the update wrote: %v", err)
+	}
+
+	// The failed state is not clean, so nothing may run on it, let alone roll it back.
+	if err := withRollback(context.Background(), false, failing); err == nil ||
+		!strings.Contains(err.Error(), "not clean") {
+		t.Errorf("withRollback() on a dirty tree = %v, want the clean-tree refusal", err)
+	}
+	if _, err := os.Stat("apis/v1alpha1/types.go"); err != nil {
+		t.Errorf("a refused update touched the tree: %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/cychiang/xp-provider-gen/pkg/plugins/crossplane/v2/core"
+	"github.com/cychiang/xp-provider-gen/pkg/version"
+)
+
+// provenanceTrailer is the git trailer on a commit `update --worktree` makes,
+// naming the generator version the provider was updated to.
+const provenanceTrailer = "xp-provider-gen-version"
+
+// updateBranch is the branch `update --worktree` commits to for a generator version.
+func updateBranch(generator string) string {
+	return "xp-provider-gen/update-" + generator
+}
+
+// updateCommitMessage is the message of the commit `update --worktree` makes.
+func updateCommitMessage(generator string) string {
+	return fmt.Sprintf(`Update provider core to xp-provider-gen %[1]s
+
+Refreshed tool-owned files and framework dependencies with 'xp-provider-gen update'
+
+%[2]s: %[1]s`, generator, provenanceTrailer)
+}
+
+// runUpdateInWorktree runs the update in a temporary worktree checked out from
+// HEAD and commits the result there on its own branch, so the user's checkout
+// — uncommitted work included — is never touched and needs no clean tree.
+func runUpdateInWorktree(ctx context.Context, opts updateOptions, keep bool) error {
+	generator := version.Get().Version
+	branch := updateBranch(generator)
+	if dirty, err := core.NewGitCommandRunner("").RunCommandWithOutput(ctx, "status", "--porcelain"); err == nil &&
+		dirty != "" {
+		fmt.Println("Uncommitted changes in this checkout are not part of the update; it starts from HEAD.")
+	}
+	commit, err := inWorktree(ctx, branch, updateCommitMessage(generator), keep,
+		func(ctx context.Context) error { return runUpdate(ctx, opts) })
+	if err != nil {
+		return err
+	}
+	if commit == "" {
+		fmt.Printf("\nProvider is already up to date with generator %s; no branch was created.\n", generator)
+		return nil
+	}
+	fmt.Printf("\nUpdate committed as %s on branch %s. Your checkout is unchanged.\n", commit, branch)
+	fmt.Printf("Review it with 'git show %s', then push it and open a pull request.\n", branch)
+	return nil
+}
+
+// inWorktree creates branch from HEAD in a new temporary worktree, runs fn with
+// that worktree as the working directory, and commits everything fn changed
+// with message. It returns the commit, or "" when fn changed nothing; then, as
+// when fn fails, the branch is deleted again. The worktree is always removed,
+// except that keep leaves a failed one in place for debugging.
+func inWorktree(ctx context.Context, branch, message string, keep bool,
+	fn func(context.Context) error,
+) (string, error) {
+	git := core.NewGitCommandRunner("")
+	if _, err := git.RunCommandWithOutput(ctx, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch); err == nil {
+		return "", fmt.Errorf("branch %s already exists; merge or delete it, then run update again", branch)
+	}
+	dir, err := os.MkdirTemp("", "xp-provider-gen-update-")
+	if err != nil {
+		return "", err
+	}
+	if err := git.RunCommand(ctx, "worktree", "add", "--quiet", "-b", branch, dir, "HEAD"); err != nil {
+		_ = os.Remove(dir)
+		return "", fmt.Errorf("creating a worktree for branch %s: %w", branch, err)
+	}
+	// cleanup runs with the caller's directory restored, and even once ctx has ended.
+	cleanup := func(dropBranch bool) error {
+		ctx := context.WithoutCancel(ctx)
+		err := git.RunCommand(ctx, "worktree", "remove", "--force", dir)
+		if err == nil && dropBranch {
+			err = git.RunCommand(ctx, "branch", "--quiet", "-D", branch)
+		}
+		return err
+	}
+
+	commit, err := commitInDir(ctx, dir, message, fn)
+	if err != nil {
+		if keep {
+			return "", fmt.Errorf("%w\n  the worktree is left at %s on branch %s (--keep-on-failure); "+
+				"run 'git worktree remove --force %[2]s && git branch -D %[3]s' to discard it", err, dir, branch)
+		}
+		if cerr := cleanup(true); cerr != nil {
+			return "", fmt.Errorf("%w\n  removing the worktree failed too (%v); "+
+				"run 'git worktree remove --force %s && git branch -D %s'", err, cerr, dir, branch)
+		}
+		return "", fmt.Errorf("%w\n  the worktree and branch %s were discarded; your checkout is unchanged", err, branch)
+	}
+	if err := cleanup(commit == ""); err != nil {
+		return commit, fmt.Errorf("removing the worktree at %s: %w", dir, err)
+	}
+	return commit, nil
+}
+
+// commitInDir runs fn with dir, a fresh worktree, as the working directory and
+// commits what it changed. The build submodule is checked out first, since the
+// make targets fn runs live there.
+func commitInDir(ctx context.Context, dir, message string, fn func(context.Context) error) (string, error) {
+	back, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	if err := os.Chdir(dir); err != nil {
+		return "", err
+	}
+	defer func() { _ = os.Chdir(back) }()
+
+	git := core.NewGitCommandRunner("")
+	if _, err := os.Stat(".gitmodules"); err == nil {
+		fmt.Println("Checking out submodules in the worktree...")
+		if err := git.RunCommand(ctx, "submodule", "update", "--init", "--recursive"); err != nil {
+			return "", fmt.Errorf("checking out submodules: %w", err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	if err := fn(ctx); err != nil {
+		return "", err
+	}
+	changed, err := git.RunCommandWithOutput(ctx, "status", "--porcelain")
+	if err != nil {
+		return "", err
+	}
+	if changed == "" {
+		return "", nil
+	}
+	if err := git.Add(ctx, "--all"); err != nil {
+		return "", err
+	}
+	if err := git.CommitWithSystemAuthor(ctx, message); err != nil {
+		return "", err
+	}
+	return git.RunCommandWithOutput(ctx, "rev-parse", "--short", "HEAD")
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestInWorktree(t *testing.T) {
+	git := gitProject(t)
+	// Work in progress the update must neither need committed nor touch.
+	_ = os.WriteFile("go.mod", []byte("module example.com/p\n\n// wip\n"), 0o644)
+	const branch = "xp-provider-gen/update-v1.0.0"
+	bump := func(context.Context) error {
+		return os.WriteFile("go.mod", []byte("module example.com/p\n\nrequire x v1\n"), 0o644)
+	}
+
+	commit, err := inWorktree(context.Background(), branch, updateCommitMessage("v1.0.0"), false, bump)
+	if err != nil || commit == "" {
+		t.Fatalf("inWorktree() = %q, %v; want a commit", commit, err)
+	}
+	if got := git("show", branch+":go.mod"); !strings.Contains(got, "require x v1") {
+		t.Errorf("go.mod on %s = %q, want the update", branch, got)
+	}
+	if got := git("log", "-1", "--format=%(trailers:key="+provenanceTrailer+",valueonly)", branch); got != "v1.0.0" {
+		t.Errorf("provenance trailer = %q, want v1.0.0", got)
+	}
+	if b, _ := os.ReadFile("go.mod"); !strings.Contains(string(b), "// wip") {
+		t.Errorf("the checkout's go.mod changed: %q", b)
+	}
+	if list := git("worktree", "list"); strings.Count(list, "\n") != 0 {
+		t.Errorf("the temporary worktree was left behind:\n%s", list)
+	}
+
+	if _, err := inWorktree(context.Background(), branch, "again", false, bump); err == nil ||
+		!strings.Contains(err.Error(), "already exists") {
+		t.Errorf("inWorktree() onto an existing branch = %v, want a refusal", err)
+	}
+
+	const failed = "xp-provider-gen/update-v1.1.0"
+	_, err = inWorktree(context.Background(), failed, "fails", false, func(ctx context.Context) error {
+		_ = bump(ctx)
+		return errors.New("make generate failed")
+	})
+	if err == nil || !strings.Contains(err.Error(), "discarded") {
+		t.Fatalf("inWorktree() with a failing update = %v, want it discarded", err)
+	}
+	if branches := git("branch", "--list", failed); branches != "" {
+		t.Errorf("branch %s survived a failed update", failed)
+	}
+
+	commit, err = inWorktree(context.Background(), failed, "no-op", false, func(context.Context) error { return nil })
+	if err != nil || commit != "" {
+		t.Errorf("inWorktree() with nothing to commit = %q, %v; want no commit", commit, err)
+	}
+	if branches := git("branch", "--list", failed); branches != "" {
+		t.Errorf("branch %s was kept without a commit", failed)
+	}
+}
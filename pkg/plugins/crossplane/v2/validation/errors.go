@@ -79,6 +79,18 @@ var (
 			"Use --force flag to overwrite existing files",
 		}},
 	}
+
+	editHints = []hintRule{
+		{"domain", []string{"Ensure domain is a valid DNS name (e.g., example.com)"}},
+		{"repository", []string{
+			"Repository should be a valid go module name",
+			"Example: github.com/example/provider-example",
+		}},
+	}
+
+	createWebhookHints = []hintRule{
+		{"storage version", []string{"Conversion between versions needs no admission webhook; see 'create-version'"}},
+	}
 )
 
 // InitError reports a failed `init` step.
@@ -91,6 +103,16 @@ func CreateAPIError(operation string, cause error) error {
 	return newPluginError("createAPI", operation, cause, createAPIHints)
 }
 
+// CreateWebhookError reports a failed `create webhook` step.
+func CreateWebhookError(operation string, cause error) error {
+	return newPluginError("createWebhook", operation, cause, createWebhookHints)
+}
+
+// EditError reports a failed `edit` step.
+func EditError(operation string, cause error) error {
+	return newPluginError("edit", operation, cause, editHints)
+}
+
 // newPluginError builds the error, attaching the hints of the first rule whose
 // substring appears in the cause.
 func newPluginError(component, operation string, cause error, rules []hintRule) error {
@@ -19,7 +19,9 @@ package validation
 import (
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
+	"text/template"
 
 	"sigs.k8s.io/kubebuilder/v4/pkg/model/resource"
 )
@@ -31,6 +33,8 @@ const (
 	fieldGroup      = "group"
 	fieldVersion    = "version"
 	fieldKind       = "kind"
+	fieldConnection = "connection key"
+	fieldExternal   = "external name"
 )
 
 // maxNameLength is the Kubernetes DNS label limit applied to groups and kinds.
@@ -44,6 +48,8 @@ var (
 	groupRe   = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`) // DNS-1123 label
 	versionRe = regexp.MustCompile(`^v\d+(alpha\d+|beta\d+)?$`)
 	kindRe    = regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`) // PascalCase
+	// A Secret data key, starting with a letter so it can name a Go constant.
+	connectionKeyRe = regexp.MustCompile(`^[a-zA-Z][-._a-zA-Z0-9]*$`)
 )
 
 // reservedKinds are Kubernetes core kinds a managed resource must not shadow.
@@ -158,6 +164,57 @@ func (v *Validator) ValidateResource(res *resource.Resource) error {
 	return v.validateKind(res.Kind)
 }
 
+// ValidateConnectionKeys validates the keys a kind publishes to its connection
+// secret: Secret data keys, each declared once.
+func (v *Validator) ValidateConnectionKeys(keys []string) error {
+	seen := map[string]bool{}
+	for _, key := range keys {
+		if err := checkPattern(fieldConnection, key, connectionKeyRe,
+			"must be a Secret key starting with a letter (e.g., endpoint, ca.crt)"); err != nil {
+			return err
+		}
+		if seen[key] {
+			return FieldValidationError{Field: fieldConnection, Value: key, Message: "is declared twice"}
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// externalNameStrategies are the strategies `create api --external-name` takes
+// besides template:<go-template>.
+var externalNameStrategies = []string{"name", "generated", "identifier"}
+
+// ValidateExternalName validates an external-name strategy: name, generated,
+// identifier, or template: followed by a Go template that parses.
+func (v *Validator) ValidateExternalName(strategy string) error {
+	if text, ok := strings.CutPrefix(strategy, "template:"); ok {
+		if strings.TrimSpace(text) == "" {
+			return FieldValidationError{
+				Field:   fieldExternal,
+				Value:   strategy,
+				Message: "needs a Go template after template: (e.g., template:{{ .Namespace }}-{{ .Name }})",
+			}
+		}
+		if _, err := template.New("external-name").Parse(text); err != nil {
+			return FieldValidationError{
+				Field:   fieldExternal,
+				Value:   strategy,
+				Message: fmt.Sprintf("is not a Go template: %v", err),
+			}
+		}
+		return nil
+	}
+	if !slices.Contains(externalNameStrategies, strategy) {
+		return FieldValidationError{
+			Field:   fieldExternal,
+			Value:   strategy,
+			Message: "must be name, generated, identifier or template:<go-template>",
+		}
+	}
+	return nil
+}
+
 // validateGroup validates API group name.
 func (v *Validator) validateGroup(group string) error {
 	if err := checkRequired(fieldGroup, group); err != nil {
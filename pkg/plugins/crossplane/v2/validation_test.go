@@ -221,3 +221,55 @@ func TestValidator_ValidateResource(t *testing.T) {
 		})
 	}
 }
+
+func TestValidator_ValidateConnectionKeys(t *testing.T) {
+	validator := validation.NewValidator()
+
+	tests := []struct {
+		name    string
+		keys    []string
+		wantErr bool
+	}{
+		{name: "valid keys", keys: []string{"endpoint", "username", "ca.crt", "private_key"}},
+		{name: "no keys"},
+		{name: "leading digit", keys: []string{"1password"}, wantErr: true},
+		{name: "not a secret key", keys: []string{"end point"}, wantErr: true},
+		{name: "declared twice", keys: []string{"endpoint", "endpoint"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.ValidateConnectionKeys(tt.keys)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateConnectionKeys() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidator_ValidateExternalName(t *testing.T) {
+	validator := validation.NewValidator()
+
+	tests := []struct {
+		name     string
+		strategy string
+		wantErr  bool
+	}{
+		{name: "name", strategy: "name"},
+		{name: "generated", strategy: "generated"},
+		{name: "identifier", strategy: "identifier"},
+		{name: "template", strategy: "template:{{ .Spec.ForProvider.Region }}-{{ .Name }}"},
+		{name: "unknown strategy", strategy: "uuid", wantErr: true},
+		{name: "empty template", strategy: "template:", wantErr: true},
+		{name: "template that does not parse", strategy: "template:{{ .Name", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.ValidateExternalName(tt.strategy)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateExternalName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -385,6 +385,30 @@ main() {
         log_error "create-test failed"; exit 1
     fi
 
+    # Step D: delete api removes a kind and commits the removal.
+    step_header "D" "delete api removes $KIND2"
+    git add -A && git commit -q -m "test: add smoke test"
+    log_info "Running: $BINARY_PATH delete api --group=$GROUP --version=$VERSION --kind=$KIND2 --purge"
+    if "$BINARY_PATH" delete api --group="$GROUP" --version="$VERSION" --kind="$KIND2" --purge; then
+        local gone
+        for gone in "internal/controller/${KIND2_LOWER}" "apis/$GROUP/$VERSION/${KIND2_LOWER}_types.go" \
+            "examples/$GROUP/${KIND2_LOWER}.yaml" "package/crds/${GROUP}.${DOMAIN}_${KIND2_LOWER}s.yaml"; do
+            if [[ -e "$gone" ]]; then
+                log_error "✗ delete api left $gone behind"; exit 1
+            fi
+        done
+        if grep -q "$KIND2" PROJECT internal/controller/register.go; then
+            log_error "✗ $KIND2 is still registered"; exit 1
+        fi
+        verify_files_exist "surviving kind" "apis/$GROUP/$VERSION/groupversion_info.go" \
+            "internal/controller/${KIND1_LOWER}/wiring.go"
+        assert_clean_tree "delete api"
+        go build ./... || { log_error "provider no longer builds after delete api"; exit 1; }
+        log_success "✓ delete api removed $KIND2 and the provider still builds"
+    else
+        log_error "delete api failed"; exit 1
+    fi
+
     # Done with the lifecycle copy — return to the pristine scaffold and drop it.
     cd "$TEST_DIR"
     rm -rf "$LIFECYCLE_DIR"
@@ -476,6 +500,7 @@ main() {
     log_success "✅ scaffolded test runs against the live provider: ${CREATE_TEST_LIVE_RESULT}"
     log_success "✅ update preserves all 3 user-owned seam files: PASSED"
     log_success "✅ update / update --adopt (on a copy): PASSED"
+    log_success "✅ delete api (on a copy): PASSED"
     echo
     log_success "🎉 All E2E tests completed successfully!"
     log_info "Pristine scaffold (single 'Initial commit', clean tree) at: $TEST_DIR"